@@ -0,0 +1,90 @@
+// Package animation provides a keyframe animation player for the Ninja robot.
+// Animations such as dances and greetings are defined as data and played
+// on a ninja.Ninja, without writing new motion code.
+package animation
+
+import (
+	"context"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+// Legs represents the angles of both legs, as passed to ninja.Ninja.MoveLegs.
+type Legs struct {
+	Left, Right int
+}
+
+// Feet represents the speeds of both feet, as passed to ninja.Ninja.SetFeetSpeed.
+type Feet struct {
+	Left, Right int
+}
+
+// Keyframe represents a single pose of an animation.
+// The legs move first, then the feet speeds are set, the note is played
+// and finally the pose is held for the hold duration.
+type Keyframe struct {
+	// Legs are the target leg angles. If nil, the legs do not move.
+	Legs *Legs
+	// Profile is the move profile used to move the legs.
+	// If nil, the profile set on the Ninja is used.
+	Profile *ninja.Profile
+	// Feet are the feet speeds. If nil, the feet keep their current speed.
+	Feet *Feet
+	// Note is the note played when the pose is reached. If nil, no note is played.
+	// Playing a note requires the Ninja to have a buzzer.
+	Note *buzzer.Note
+	// Hold is the duration for which the pose is held.
+	Hold time.Duration
+}
+
+// Animation represents a sequence of keyframes.
+type Animation struct {
+	// Name is the name of the animation.
+	Name string
+	// Keyframes are the keyframes of the animation, played in order.
+	Keyframes []Keyframe
+	// Repeat is the number of times the keyframes are played. Zero plays them once.
+	Repeat int
+}
+
+// Play plays the animation on the given Ninja and stops the feet when it is done.
+// It blocks until the animation is done, or until ctx is cancelled, in which case the
+// motion stops and the context error is returned.
+func Play(ctx context.Context, n *ninja.Ninja, a Animation) error {
+	for range max(a.Repeat, 1) {
+		for _, kf := range a.Keyframes {
+			if err := playKeyframe(ctx, n, kf); err != nil {
+				n.SetFeetSpeed(0, 0)
+				return err
+			}
+		}
+	}
+	return n.SetFeetSpeed(0, 0)
+}
+
+func playKeyframe(ctx context.Context, n *ninja.Ninja, kf Keyframe) error {
+	if kf.Legs != nil {
+		var err error
+		if kf.Profile != nil {
+			err = n.MoveLegsProfileContext(ctx, kf.Legs.Left, kf.Legs.Right, *kf.Profile)
+		} else {
+			err = n.MoveLegsContext(ctx, kf.Legs.Left, kf.Legs.Right)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if kf.Feet != nil {
+		if err := n.SetFeetSpeed(kf.Feet.Left, kf.Feet.Right); err != nil {
+			return err
+		}
+	}
+	if kf.Note != nil {
+		if err := n.BuzzerToneContext(ctx, *kf.Note); err != nil {
+			return err
+		}
+	}
+	return n.PauseContext(ctx, kf.Hold)
+}
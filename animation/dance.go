@@ -0,0 +1,161 @@
+package animation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/clock"
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+var (
+	ErrInvalidBPM   = errors.New("animation: invalid BPM")
+	ErrVirtualClock = errors.New("animation: dance with music and moves played on a virtual clock")
+)
+
+// Note represents a note of a dance's music track, with its length in beats.
+type Note struct {
+	Period buzzer.NotePeriod
+	Beats  float64
+}
+
+// Cue represents a motion of a dance's motion track, starting at the given beat.
+type Cue struct {
+	// Beat is the beat at which the motion starts, counted from zero.
+	Beat float64
+	// Motion is the motion to perform.
+	Motion ninja.Motion
+}
+
+// Dance represents a melody and a sequence of motions played together,
+// aligned to a common beat grid so the moves land on the notes.
+type Dance struct {
+	// BPM is the tempo of the dance in beats per minute.
+	BPM float64
+	// Music is the melody. Each note starts when the previous one ends.
+	Music []Note
+	// Moves are the motions, sorted by their starting beat.
+	Moves []Cue
+}
+
+// beat returns the duration of the given number of beats.
+func (d Dance) beat(beats float64) time.Duration {
+	return time.Duration(beats * float64(time.Minute) / d.BPM)
+}
+
+// Motion returns the keyframe as a motion, so it can be used as a dance cue
+// or submitted to a ninja.Engine.
+func (kf Keyframe) Motion() ninja.Motion {
+	return func(ctx context.Context, n *ninja.Ninja) error {
+		return playKeyframe(ctx, n, kf)
+	}
+}
+
+// PlayDance plays the dance's music on the buzzer and its motions on the Ninja at the same time.
+// Both tracks are scheduled against the beat grid from a common start time, so a late note or a
+// motion that overruns its beats does not delay the rest of the dance.
+// The clock must be the one used by the Ninja and the buzzer. If it is nil, the system clock is used.
+// PlayDance blocks until both tracks are done, or until ctx is cancelled, and stops the feet at the end.
+// If a track fails, the other one is stopped and the error of the failed track is returned.
+//
+// It returns ErrInvalidBPM if the tempo of the dance is not positive, and ninja.ErrBuzzerNotConfigured
+// if the dance has music but the buzzer is nil. Since the two tracks run at the same time and each of
+// them would advance a virtual clock on its own, a dance with both music and moves can not be played
+// on a clock.Virtual and ErrVirtualClock is returned.
+func PlayDance(ctx context.Context, n *ninja.Ninja, bz *buzzer.Buzzer, clk clock.Clock, d Dance) error {
+	if !(d.BPM > 0) {
+		return ErrInvalidBPM
+	}
+	if bz == nil && len(d.Music) > 0 {
+		return ninja.ErrBuzzerNotConfigured
+	}
+	if clk == nil {
+		clk = clock.System
+	}
+	if _, ok := clk.(*clock.Virtual); ok && len(d.Music) > 0 && len(d.Moves) > 0 {
+		return ErrVirtualClock
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the first failing track stops the other one, which then fails with the cancellation
+	var (
+		once  sync.Once
+		first error
+	)
+	fail := func(err error) {
+		if err != nil {
+			once.Do(func() {
+				first = err
+				cancel()
+			})
+		}
+	}
+
+	start := clk.Now()
+	musicDone := make(chan struct{})
+	go func() {
+		defer close(musicDone)
+		fail(playMusic(ctx, bz, clk, start, d))
+	}()
+
+	fail(playMoves(ctx, n, clk, start, d))
+	stopErr := n.SetFeetSpeed(0, 0)
+	<-musicDone
+	if first != nil {
+		return first
+	}
+	return stopErr
+}
+
+func playMusic(ctx context.Context, bz *buzzer.Buzzer, clk clock.Clock, start time.Time, d Dance) error {
+	var beats float64
+	for _, note := range d.Music {
+		if err := waitUntil(ctx, clk, start.Add(d.beat(beats))); err != nil {
+			return err
+		}
+		beats += note.Beats
+		// end the note on time even if it started late
+		duration := start.Add(d.beat(beats)).Sub(clk.Now())
+		if duration <= 0 {
+			continue
+		}
+		if err := bz.ToneContext(ctx, buzzer.Note{Period: note.Period, Duration: duration}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func playMoves(ctx context.Context, n *ninja.Ninja, clk clock.Clock, start time.Time, d Dance) error {
+	for _, cue := range d.Moves {
+		if err := waitUntil(ctx, clk, start.Add(d.beat(cue.Beat))); err != nil {
+			return err
+		}
+		if err := cue.Motion(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitUntil waits until the given time or until ctx is cancelled.
+// It returns immediately if the time has already passed.
+func waitUntil(ctx context.Context, clk clock.Clock, t time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d := t.Sub(clk.Now())
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clk.After(d):
+		return nil
+	}
+}
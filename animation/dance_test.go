@@ -0,0 +1,113 @@
+package animation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/animation"
+	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/clock"
+	"github.com/HattoriHanzo031/gotto/ninja"
+	"github.com/HattoriHanzo031/gotto/sim"
+)
+
+var errPwm = errors.New("pwm failure")
+
+// brokenPwm is a PWM channel that fails to set the period.
+type brokenPwm struct{}
+
+func (brokenPwm) Configure() error       { return nil }
+func (brokenPwm) Top() uint32            { return 1000 }
+func (brokenPwm) SetPeriod(uint64) error { return errPwm }
+func (brokenPwm) SetDuty(uint32)         {}
+
+func wave(ctx context.Context, n *ninja.Ninja) error {
+	return n.MoveLegsContext(ctx, 90, 120)
+}
+
+func TestPlayDanceErrors(t *testing.T) {
+	music := []animation.Note{{Period: buzzer.C4, Beats: 1}}
+	moves := []animation.Cue{{Beat: 0, Motion: wave}, {Beat: 8, Motion: wave}}
+
+	tests := []struct {
+		name    string
+		virtual bool
+		bz      func(clock.Clock) *buzzer.Buzzer
+		dance   animation.Dance
+		want    error
+	}{
+		{
+			name:  "zero BPM",
+			dance: animation.Dance{Moves: moves},
+			want:  animation.ErrInvalidBPM,
+		},
+		{
+			name:  "music without buzzer",
+			dance: animation.Dance{BPM: 120, Music: music, Moves: moves},
+			want:  ninja.ErrBuzzerNotConfigured,
+		},
+		{
+			name:    "music and moves on a virtual clock",
+			virtual: true,
+			bz:      func(clk clock.Clock) *buzzer.Buzzer { return buzzer.New(sim.NewPiezo(clk, 8000), clk) },
+			dance:   animation.Dance{BPM: 120, Music: music, Moves: moves},
+			want:    animation.ErrVirtualClock,
+		},
+		{
+			name:  "music track fails",
+			bz:    func(clk clock.Clock) *buzzer.Buzzer { return buzzer.New(brokenPwm{}, clk) },
+			dance: animation.Dance{BPM: 600, Music: music, Moves: moves},
+			want:  errPwm,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clk clock.Clock = clock.System
+			if tt.virtual {
+				clk = clock.NewVirtual(time.Time{})
+			}
+			var bz *buzzer.Buzzer
+			if tt.bz != nil {
+				bz = tt.bz(clk)
+			}
+			n := sim.NewRobot(clk).Ninja(bz)
+			err := animation.PlayDance(context.Background(), n, bz, clk, tt.dance)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("PlayDance() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayDanceMovesOnBeat(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	r := sim.NewRobot(clk)
+	n := r.Ninja(nil)
+
+	var starts []time.Duration
+	start := clk.Now()
+	cue := func(ctx context.Context, n *ninja.Ninja) error {
+		starts = append(starts, clk.Now().Sub(start))
+		return wave(ctx, n)
+	}
+	d := animation.Dance{
+		BPM:   120,
+		Moves: []animation.Cue{{Beat: 0, Motion: cue}, {Beat: 2, Motion: cue}, {Beat: 2.1, Motion: cue}},
+	}
+	if err := animation.PlayDance(context.Background(), n, nil, clk, d); err != nil {
+		t.Fatalf("PlayDance() error = %v", err)
+	}
+
+	// the third cue starts late, since the second move takes 150ms and overruns it
+	want := []time.Duration{0, time.Second, time.Second + 150*time.Millisecond}
+	if len(starts) != len(want) {
+		t.Fatalf("moves started at %v, want %v", starts, want)
+	}
+	for i := range want {
+		if starts[i] != want[i] {
+			t.Errorf("move %d started at %v, want %v", i, starts[i], want[i])
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package buzzer
 
 import (
+	"context"
+	"sync"
 	"time"
+
+	"github.com/HattoriHanzo031/gotto/clock"
 )
 
 // PwmChannel is an interface that abstracts PwmChannel functionality.
@@ -16,54 +20,54 @@ type PwmChannel interface {
 	SetDuty(value uint32)
 }
 
-// NotePeriod represents the period of a note frequency in microseconds.
-type NotePeriod int
-
-const (
-	C3  NotePeriod = 7634
-	D3  NotePeriod = 6803
-	E3  NotePeriod = 6061
-	F3  NotePeriod = 5714
-	G3  NotePeriod = 5102
-	A3  NotePeriod = 4545
-	B3  NotePeriod = 4049
-	C4  NotePeriod = 3816 // 261 Hz
-	D4  NotePeriod = 3401 // 294 Hz
-	E4  NotePeriod = 3030 // 329 Hz
-	F4  NotePeriod = 2865 // 349 Hz
-	G4  NotePeriod = 2551 // 392 Hz
-	A4  NotePeriod = 2272 // 440 Hz
-	A4s NotePeriod = 2146
-	B4  NotePeriod = 2028 // 493 Hz
-	C5  NotePeriod = 1912 // 523 Hz
-	D5  NotePeriod = 1706
-	D5s NotePeriod = 1608
-	E5  NotePeriod = 1517 // 659 Hz
-	F5  NotePeriod = 1433 // 698 Hz
-	G5s NotePeriod = 2408 // 784 Hz
-	A5  NotePeriod = 1136
-	A5s NotePeriod = 1073
-	B5  NotePeriod = 1012
-	C6  NotePeriod = 955
-
-	Silence NotePeriod = 0
-)
-
 // Note represents a musical note with a specific frequency (period) and duration.
 type Note struct {
 	Period   NotePeriod // in microseconds
 	Duration time.Duration
+	// Chord holds the other notes of a chord played with Period. Since the buzzer plays one note
+	// at a time, the notes of a chord are arpeggiated, switching between them at the arpeggio rate.
+	Chord Chord
+}
+
+// Chord represents up to three notes played together with the period of a note.
+// Unused notes are Silence.
+type Chord [3]NotePeriod
+
+// pitches returns the notes sounding during the note, without Silence.
+func (n Note) pitches() []NotePeriod {
+	pitches := make([]NotePeriod, 0, 1+len(n.Chord))
+	if n.Period != Silence {
+		pitches = append(pitches, n.Period)
+	}
+	for _, p := range n.Chord {
+		if p != Silence {
+			pitches = append(pitches, p)
+		}
+	}
+	return pitches
 }
 
 // Buzzer represents a buzzer that can play musical notes using a PWM channel.
 type Buzzer struct {
-	ch PwmChannel
+	ch     PwmChannel
+	clock  clock.Clock
+	mu     sync.Mutex
+	volume int
+	timbre Timbre
+	arp    time.Duration
 }
 
 // New creates a new Buzzer instance with the given PwmChannel.
-func New(pwm PwmChannel) *Buzzer {
+// The clock is used for note timing. If it is nil, the system clock is used.
+func New(pwm PwmChannel, clk clock.Clock) *Buzzer {
+	if clk == nil {
+		clk = clock.System
+	}
 	return &Buzzer{
-		ch: pwm,
+		ch:     pwm,
+		clock:  clk,
+		volume: MaxVolume,
+		arp:    time.Second / DefaultArpeggioRate,
 	}
 }
 
@@ -74,24 +78,29 @@ func (b *Buzzer) Configure() error {
 
 // Tone plays a single note on the buzzer.
 func (b *Buzzer) Tone(note Note) error {
-	if note.Period == Silence {
-		b.ch.SetDuty(0)
-		time.Sleep(note.Duration)
-		return nil
-	}
-	err := b.ch.SetPeriod(uint64(note.Period * 1000))
-	if err != nil {
-		return err
-	}
-	b.ch.SetDuty(b.ch.Top() / 2)
-	time.Sleep(note.Duration)
-	b.ch.SetDuty(0)
-	return nil
+	return b.ToneContext(context.Background(), note)
+}
+
+// ToneContext plays a single note on the buzzer.
+// If ctx is cancelled, the note is cut short and the context error is returned.
+func (b *Buzzer) ToneContext(ctx context.Context, note Note) error {
+	_, err := b.sound(ctx, note, 0, nil)
+	return err
 }
 
 // PlayMelody plays a sequence of notes (melody) on the buzzer.
-func (b *Buzzer) PlayMelody(melody []Note) {
+// It stops at the first note that fails and returns its error.
+func (b *Buzzer) PlayMelody(melody []Note) error {
+	return b.PlayMelodyContext(context.Background(), melody)
+}
+
+// PlayMelodyContext plays a sequence of notes (melody) on the buzzer.
+// It stops at the first note that fails, or when ctx is cancelled, and returns the error.
+func (b *Buzzer) PlayMelodyContext(ctx context.Context, melody []Note) error {
 	for _, note := range melody {
-		b.Tone(note)
+		if err := b.ToneContext(ctx, note); err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -0,0 +1,134 @@
+package buzzer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrMelodyNotFound = errors.New("buzzer: melody not found")
+
+// Melody represents a named melody in the melody registry.
+type Melody struct {
+	Name  string
+	Notes []Note
+}
+
+// ImperialMarch is the opening of the Imperial March, registered as "imperial-march".
+var ImperialMarch = []Note{
+	{Period: A4, Duration: 500 * time.Millisecond},
+	{Period: Silence, Duration: 200 * time.Millisecond},
+	{Period: A4, Duration: 500 * time.Millisecond},
+	{Period: Silence, Duration: 200 * time.Millisecond},
+	{Period: A4, Duration: 500 * time.Millisecond},
+	{Period: Silence, Duration: 200 * time.Millisecond},
+	{Period: F4, Duration: 400 * time.Millisecond},
+	{Period: Silence, Duration: 50 * time.Millisecond},
+	{Period: C5, Duration: 200 * time.Millisecond},
+	{Period: Silence, Duration: 50 * time.Millisecond},
+	{Period: A4, Duration: 600 * time.Millisecond},
+	{Period: Silence, Duration: 100 * time.Millisecond},
+	{Period: F4, Duration: 400 * time.Millisecond},
+	{Period: Silence, Duration: 50 * time.Millisecond},
+	{Period: C5, Duration: 200 * time.Millisecond},
+	{Period: Silence, Duration: 50 * time.Millisecond},
+	{Period: A4, Duration: 600 * time.Millisecond},
+	{Period: Silence, Duration: 800 * time.Millisecond},
+	{Period: E5, Duration: 500 * time.Millisecond},
+	{Period: Silence, Duration: 200 * time.Millisecond},
+	{Period: E5, Duration: 500 * time.Millisecond},
+	{Period: Silence, Duration: 200 * time.Millisecond},
+	{Period: E5, Duration: 500 * time.Millisecond},
+	{Period: Silence, Duration: 200 * time.Millisecond},
+	{Period: F5, Duration: 400 * time.Millisecond},
+	{Period: Silence, Duration: 50 * time.Millisecond},
+	{Period: C5, Duration: 200 * time.Millisecond},
+	{Period: Silence, Duration: 50 * time.Millisecond},
+	{Period: G4s, Duration: 600 * time.Millisecond},
+	{Period: Silence, Duration: 100 * time.Millisecond},
+	{Period: F4, Duration: 400 * time.Millisecond},
+	{Period: Silence, Duration: 50 * time.Millisecond},
+	{Period: C5, Duration: 200 * time.Millisecond},
+	{Period: Silence, Duration: 50 * time.Millisecond},
+	{Period: A4, Duration: 600 * time.Millisecond},
+	{Period: Silence, Duration: 400 * time.Millisecond},
+}
+
+// StarWarsTheme is the opening of the Star Wars main theme, registered as "star-wars".
+var StarWarsTheme = []Note{
+	{Period: F4, Duration: 210 * time.Millisecond},
+	{Period: F4, Duration: 210 * time.Millisecond},
+	{Period: F4, Duration: 210 * time.Millisecond},
+	{Period: A4s, Duration: 1280 * time.Millisecond},
+	{Period: F5, Duration: 1280 * time.Millisecond},
+	{Period: D5s, Duration: 210 * time.Millisecond},
+	{Period: D5, Duration: 210 * time.Millisecond},
+	{Period: C5, Duration: 210 * time.Millisecond},
+	{Period: A5s, Duration: 1280 * time.Millisecond},
+	{Period: F5, Duration: 640 * time.Millisecond},
+	{Period: D5s, Duration: 210 * time.Millisecond},
+	{Period: D5, Duration: 210 * time.Millisecond},
+	{Period: C5, Duration: 210 * time.Millisecond},
+	{Period: A5s, Duration: 1280 * time.Millisecond},
+	{Period: F5, Duration: 640 * time.Millisecond},
+	{Period: D5s, Duration: 210 * time.Millisecond},
+	{Period: D5, Duration: 210 * time.Millisecond},
+	{Period: D5s, Duration: 210 * time.Millisecond},
+	{Period: C5, Duration: 1280 * time.Millisecond},
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = []Melody{
+		{Name: "imperial-march", Notes: ImperialMarch},
+		{Name: "star-wars", Notes: StarWarsTheme},
+	}
+)
+
+// RegisterMelody adds the melody to the registry under the given name and returns its index.
+// Indexes are assigned in registration order after the built-in melodies, so they are stable
+// as long as melodies are registered in the same order, for example from an init function.
+// If a melody with the same name is already registered, it is replaced and keeps its index.
+func RegisterMelody(name string, notes []Note) int {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, m := range registry {
+		if strings.EqualFold(m.Name, name) {
+			registry[i].Notes = notes
+			return i
+		}
+	}
+	registry = append(registry, Melody{Name: name, Notes: notes})
+	return len(registry) - 1
+}
+
+// LookupMelody returns the notes of the registered melody with the given name, ignoring case.
+func LookupMelody(name string) ([]Note, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, m := range registry {
+		if strings.EqualFold(m.Name, name) {
+			return m.Notes, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrMelodyNotFound, name)
+}
+
+// MelodyAt returns the registered melody with the given index.
+func MelodyAt(index int) (Melody, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if index < 0 || index >= len(registry) {
+		return Melody{}, fmt.Errorf("%w: index %d", ErrMelodyNotFound, index)
+	}
+	return registry[index], nil
+}
+
+// Melodies returns all registered melodies in index order.
+func Melodies() []Melody {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]Melody(nil), registry...)
+}
@@ -0,0 +1,310 @@
+package buzzer
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidMIDI     = errors.New("buzzer: invalid MIDI file")
+	ErrUnsupportedMIDI = errors.New("buzzer: unsupported MIDI file")
+	ErrMIDITrack       = errors.New("buzzer: MIDI track not found")
+)
+
+// defaultTempo is the tempo of a MIDI file without tempo events, in microseconds per quarter note (120 BPM).
+const defaultTempo = 500000
+
+// percussionChannel is the MIDI channel reserved for drums, which have no pitch (channel 10).
+const percussionChannel = 9
+
+// midiEvent is a note or tempo event at an absolute time in ticks.
+type midiEvent struct {
+	tick  uint64
+	kind  byte // midiNoteOn, midiNoteOff or midiTempo
+	key   int
+	tempo uint32 // in microseconds per quarter note
+}
+
+const (
+	midiNoteOff byte = iota
+	midiNoteOn
+	midiTempo
+)
+
+// ParseMIDI decodes a Standard MIDI File and returns the notes of the given track as a melody.
+// Type 0 files have a single track, numbered 0. For type 1 files, the track is the index of the
+// track in the file, and tempo changes are taken from all tracks.
+//
+// Since the buzzer plays one note at a time, the highest note is played when several notes sound
+// at the same time, and gaps between notes are filled with Silence. Notes on the percussion channel
+// are ignored. Files with SMPTE time division are not supported.
+func ParseMIDI(r io.Reader, track int) ([]Note, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkType, header, data, err := readChunk(data)
+	if err != nil {
+		return nil, err
+	}
+	if chunkType != "MThd" || len(header) < 6 {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidMIDI)
+	}
+	format := binary.BigEndian.Uint16(header[0:])
+	tracks := int(binary.BigEndian.Uint16(header[2:]))
+	division := binary.BigEndian.Uint16(header[4:])
+	switch {
+	case format > 1:
+		return nil, fmt.Errorf("%w: type %d", ErrUnsupportedMIDI, format)
+	case division&0x8000 != 0:
+		return nil, fmt.Errorf("%w: SMPTE time division", ErrUnsupportedMIDI)
+	case division == 0:
+		return nil, fmt.Errorf("%w: zero time division", ErrInvalidMIDI)
+	case track < 0 || track >= tracks:
+		return nil, fmt.Errorf("%w: track %d of %d", ErrMIDITrack, track, tracks)
+	}
+
+	var tempos, notes []midiEvent
+	for i := 0; i < tracks; {
+		var chunk []byte
+		chunkType, chunk, data, err = readChunk(data)
+		if err != nil {
+			return nil, err
+		}
+		if chunkType != "MTrk" {
+			// unknown chunks must be ignored
+			continue
+		}
+		events, err := parseTrack(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("%w (track %d)", err, i)
+		}
+		for _, e := range events {
+			switch {
+			case e.kind == midiTempo:
+				tempos = append(tempos, e)
+			case i == track:
+				notes = append(notes, e)
+			}
+		}
+		i++
+	}
+
+	slices.SortStableFunc(tempos, func(a, b midiEvent) int {
+		return cmp.Compare(a.tick, b.tick)
+	})
+	return monophonic(notes, tempoMap{division: uint64(division), changes: tempos}), nil
+}
+
+// readChunk returns the type and data of the first chunk in data and the rest of data.
+func readChunk(data []byte) (string, []byte, []byte, error) {
+	if len(data) < 8 {
+		return "", nil, nil, fmt.Errorf("%w: unexpected end of file", ErrInvalidMIDI)
+	}
+	size := binary.BigEndian.Uint32(data[4:])
+	if uint64(size) > uint64(len(data)-8) {
+		return "", nil, nil, fmt.Errorf("%w: chunk longer than the file", ErrInvalidMIDI)
+	}
+	return string(data[:4]), data[8 : 8+size], data[8+size:], nil
+}
+
+// readVarLen reads a variable-length quantity and returns it with the number of bytes read.
+func readVarLen(data []byte) (uint64, int, error) {
+	var v uint64
+	for i := 0; i < len(data) && i < 4; i++ {
+		v = v<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: invalid variable-length quantity", ErrInvalidMIDI)
+}
+
+// parseTrack returns the note and tempo events of a track chunk.
+func parseTrack(data []byte) ([]midiEvent, error) {
+	var (
+		events  []midiEvent
+		tick    uint64
+		running byte
+	)
+	truncated := fmt.Errorf("%w: truncated event", ErrInvalidMIDI)
+	for len(data) > 0 {
+		delta, n, err := readVarLen(data)
+		if err != nil {
+			return nil, err
+		}
+		tick += delta
+		data = data[n:]
+		if len(data) == 0 {
+			return nil, truncated
+		}
+
+		status := data[0]
+		if status < 0x80 {
+			// running status: the status of the previous channel message is reused
+			if running == 0 {
+				return nil, fmt.Errorf("%w: data byte without status", ErrInvalidMIDI)
+			}
+			status = running
+		} else {
+			data = data[1:]
+		}
+
+		switch {
+		case status == 0xff:
+			if len(data) == 0 {
+				return nil, truncated
+			}
+			metaType := data[0]
+			length, n, err := readVarLen(data[1:])
+			if err != nil {
+				return nil, err
+			}
+			data = data[1+n:]
+			if uint64(len(data)) < length {
+				return nil, truncated
+			}
+			meta := data[:length]
+			data = data[length:]
+			switch {
+			case metaType == 0x2f:
+				return events, nil
+			case metaType == 0x51 && len(meta) == 3:
+				t := uint32(meta[0])<<16 | uint32(meta[1])<<8 | uint32(meta[2])
+				events = append(events, midiEvent{tick: tick, kind: midiTempo, tempo: t})
+			}
+
+		case status == 0xf0 || status == 0xf7:
+			length, n, err := readVarLen(data)
+			if err != nil {
+				return nil, err
+			}
+			if uint64(len(data)-n) < length {
+				return nil, truncated
+			}
+			data = data[uint64(n)+length:]
+			running = 0
+
+		case status >= 0x80 && status < 0xf0:
+			running = status
+			size := 2
+			if kind := status & 0xf0; kind == 0xc0 || kind == 0xd0 {
+				size = 1
+			}
+			if len(data) < size {
+				return nil, truncated
+			}
+			msg := data[:size]
+			data = data[size:]
+			if status&0x0f == percussionChannel {
+				continue
+			}
+			switch status & 0xf0 {
+			case 0x90:
+				kind := midiNoteOn
+				if msg[1] == 0 {
+					kind = midiNoteOff
+				}
+				events = append(events, midiEvent{tick: tick, kind: kind, key: int(msg[0] & 0x7f)})
+			case 0x80:
+				events = append(events, midiEvent{tick: tick, kind: midiNoteOff, key: int(msg[0] & 0x7f)})
+			}
+
+		default:
+			return nil, fmt.Errorf("%w: unexpected status byte %#x", ErrInvalidMIDI, status)
+		}
+	}
+	return events, nil
+}
+
+// tempoMap converts ticks to time according to the tempo changes.
+type tempoMap struct {
+	division uint64 // ticks per quarter note
+	changes  []midiEvent
+}
+
+// at returns the time from the start of the file at the given tick.
+func (m tempoMap) at(tick uint64) time.Duration {
+	var (
+		elapsed time.Duration
+		last    uint64
+		current uint64 = defaultTempo
+	)
+	for _, c := range m.changes {
+		if c.tick >= tick {
+			break
+		}
+		elapsed += m.duration(c.tick-last, current)
+		last, current = c.tick, uint64(c.tempo)
+	}
+	return elapsed + m.duration(tick-last, current)
+}
+
+func (m tempoMap) duration(ticks, tempo uint64) time.Duration {
+	return time.Duration(ticks * tempo * uint64(time.Microsecond) / m.division)
+}
+
+// monophonic converts the note events to a melody playing the highest sounding note.
+// A note struck again starts a new note, even if its pitch does not change.
+func monophonic(events []midiEvent, tempos tempoMap) []Note {
+	var (
+		melody  []Note
+		active  [128]int
+		current = -1 // key of the sounding note, or -1 for silence
+		start   uint64
+	)
+	highest := func() int {
+		for key := len(active) - 1; key >= 0; key-- {
+			if active[key] > 0 {
+				return key
+			}
+		}
+		return -1
+	}
+	emit := func(tick uint64) {
+		if tick == start {
+			return
+		}
+		note := Note{Duration: tempos.at(tick) - tempos.at(start)}
+		if current >= 0 {
+			note.Period = FromMIDI(current)
+		}
+		melody = append(melody, note)
+	}
+
+	for i := 0; i < len(events); {
+		tick := events[i].tick
+		struck := -1
+		// apply all events at the same tick, note offs first
+		j := i
+		for ; j < len(events) && events[j].tick == tick; j++ {
+			if e := events[j]; e.kind == midiNoteOff && active[e.key] > 0 {
+				active[e.key]--
+			}
+		}
+		for k := i; k < j; k++ {
+			if e := events[k]; e.kind == midiNoteOn {
+				active[e.key]++
+				struck = max(struck, e.key)
+			}
+		}
+		i = j
+
+		next := highest()
+		if next != current || (next >= 0 && next == struck) {
+			emit(tick)
+			current, start = next, tick
+		}
+	}
+	// trailing silence is dropped
+	if current >= 0 && len(events) > 0 {
+		emit(events[len(events)-1].tick)
+	}
+	return melody
+}
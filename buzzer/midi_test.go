@@ -0,0 +1,172 @@
+package buzzer_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+)
+
+// chunk returns a MIDI chunk with the given type and data.
+func chunk(chunkType string, data ...byte) []byte {
+	b := append([]byte(chunkType), 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[4:], uint32(len(data)))
+	return append(b, data...)
+}
+
+// header returns the header chunk of a MIDI file.
+func header(format, tracks, division uint16) []byte {
+	return chunk("MThd", byte(format>>8), byte(format), byte(tracks>>8), byte(tracks), byte(division>>8), byte(division))
+}
+
+func midiFile(chunks ...[]byte) []byte {
+	return slices.Concat(chunks...)
+}
+
+// conductorTrack sets the tempo to 600000µs per quarter note (100 BPM).
+var conductorTrack = chunk("MTrk",
+	0x00, 0xff, 0x51, 0x03, 0x09, 0x27, 0xc0,
+	0x00, 0xff, 0x2f, 0x00,
+)
+
+// noteTrack is a track with a division of 96 ticks per quarter note.
+var noteTrack = chunk("MTrk",
+	// drum on the percussion channel, ignored
+	0x00, 0x99, 0x24, 0x64,
+	// C4 quarter, released with running status and velocity 0
+	0x00, 0x90, 0x3c, 0x64,
+	0x60, 0x3c, 0x00,
+	// E4 and G4 chord quarter, the highest note is played
+	0x00, 0x40, 0x64,
+	0x00, 0x43, 0x64,
+	0x60, 0x40, 0x00,
+	0x00, 0x43, 0x00,
+	// eighth rest, then A4 struck twice
+	0x30, 0x45, 0x64,
+	0x30, 0x45, 0x00,
+	0x00, 0x45, 0x64,
+	0x30, 0x45, 0x00,
+	// 128 ticks rest with a two byte delta and a sysex clearing the running status
+	0x81, 0x00, 0xf0, 0x03, 0x7e, 0x7f, 0xf7,
+	// C5 quarter, released with note off
+	0x00, 0x90, 0x48, 0x64,
+	0x60, 0x80, 0x48, 0x40,
+	0x00, 0xff, 0x2f, 0x00,
+)
+
+func TestParseMIDI(t *testing.T) {
+	notes := func(quarter time.Duration) []buzzer.Note {
+		return []buzzer.Note{
+			{Period: buzzer.FromMIDI(60), Duration: quarter},
+			{Period: buzzer.FromMIDI(67), Duration: quarter},
+			{Period: buzzer.Silence, Duration: quarter / 2},
+			{Period: buzzer.FromMIDI(69), Duration: quarter / 2},
+			{Period: buzzer.FromMIDI(69), Duration: quarter / 2},
+			{Period: buzzer.Silence, Duration: quarter * 4 / 3},
+			{Period: buzzer.FromMIDI(72), Duration: quarter},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		file  []byte
+		track int
+		want  []buzzer.Note
+	}{
+		{
+			name: "type 0 with default tempo",
+			file: midiFile(header(0, 1, 96), noteTrack),
+			want: notes(500 * time.Millisecond),
+		},
+		{
+			name:  "type 1 with conductor track",
+			file:  midiFile(header(1, 2, 96), conductorTrack, noteTrack),
+			track: 1,
+			want:  notes(600 * time.Millisecond),
+		},
+		{
+			name:  "type 1 skips unknown chunks",
+			file:  midiFile(header(1, 2, 96), chunk("XFIH", 1, 2, 3), conductorTrack, noteTrack),
+			track: 1,
+			want:  notes(600 * time.Millisecond),
+		},
+		{
+			name: "type 1 conductor track has no notes",
+			file: midiFile(header(1, 2, 96), conductorTrack, noteTrack),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buzzer.ParseMIDI(bytes.NewReader(tt.file), tt.track)
+			if err != nil {
+				t.Fatalf("ParseMIDI() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseMIDI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMIDIErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  []byte
+		track int
+		want  error
+	}{
+		{
+			name:  "track out of range",
+			file:  midiFile(header(1, 2, 96), conductorTrack, noteTrack),
+			track: 2,
+			want:  buzzer.ErrMIDITrack,
+		},
+		{
+			name: "type 2",
+			file: midiFile(header(2, 1, 96), noteTrack),
+			want: buzzer.ErrUnsupportedMIDI,
+		},
+		{
+			name: "SMPTE time division",
+			file: midiFile(header(0, 1, 0xe728), noteTrack),
+			want: buzzer.ErrUnsupportedMIDI,
+		},
+		{
+			name: "missing header",
+			file: noteTrack,
+			want: buzzer.ErrInvalidMIDI,
+		},
+		{
+			name: "missing track",
+			file: header(0, 1, 96),
+			want: buzzer.ErrInvalidMIDI,
+		},
+		{
+			name: "chunk longer than the file",
+			file: midiFile(header(0, 1, 96), noteTrack[:len(noteTrack)-4]),
+			want: buzzer.ErrInvalidMIDI,
+		},
+		{
+			name: "data byte without status",
+			file: midiFile(header(0, 1, 96), chunk("MTrk", 0x00, 0x3c, 0x64)),
+			want: buzzer.ErrInvalidMIDI,
+		},
+		{
+			name: "truncated event",
+			file: midiFile(header(0, 1, 96), chunk("MTrk", 0x00, 0x90, 0x3c)),
+			want: buzzer.ErrInvalidMIDI,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buzzer.ParseMIDI(bytes.NewReader(tt.file), tt.track)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ParseMIDI() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
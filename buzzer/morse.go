@@ -0,0 +1,145 @@
+package buzzer
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// morseCodes maps the characters that can be sent in Morse code to their dots and dashes.
+var morseCodes = map[rune]string{
+	'a': ".-", 'b': "-...", 'c': "-.-.", 'd': "-..", 'e': ".", 'f': "..-.",
+	'g': "--.", 'h': "....", 'i': "..", 'j': ".---", 'k': "-.-", 'l': ".-..",
+	'm': "--", 'n': "-.", 'o': "---", 'p': ".--.", 'q': "--.-", 'r': ".-.",
+	's': "...", 't': "-", 'u': "..-", 'v': "...-", 'w': ".--", 'x': "-..-",
+	'y': "-.--", 'z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-",
+	'5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+	'.': ".-.-.-", ',': "--..--", '?': "..--..", '\'': ".----.", '!': "-.-.--",
+	'/': "-..-.", '(': "-.--.", ')': "-.--.-", '&': ".-...", ':': "---...",
+	';': "-.-.-.", '=': "-...-", '+': ".-.-.", '-': "-....-", '_': "..--.-",
+	'"': ".-..-.", '@': ".--.-.",
+}
+
+// Morse renders text as Morse code.
+type Morse struct {
+	// WPM is the speed in words per minute, where a dot lasts 1.2 seconds divided by WPM.
+	WPM int
+	// Pitch is the note the dots and dashes are played with.
+	Pitch NotePeriod
+}
+
+// DefaultMorse sends Morse code at 15 words per minute on A5.
+var DefaultMorse = Morse{
+	WPM:   15,
+	Pitch: A5,
+}
+
+// Encode returns the text as a melody of Morse code. Letters are not case sensitive,
+// and characters without a Morse code are skipped.
+// A dash lasts three dots, the gap between the dots and dashes of a character one dot,
+// the gap between characters three dots and the gap between words seven dots.
+// If WPM or Pitch is zero, the value of DefaultMorse is used.
+func (m Morse) Encode(text string) []Note {
+	if m.WPM <= 0 {
+		m.WPM = DefaultMorse.WPM
+	}
+	if m.Pitch == Silence {
+		m.Pitch = DefaultMorse.Pitch
+	}
+	dot := 1200 * time.Millisecond / time.Duration(m.WPM)
+
+	var melody []Note
+	gap := 0 // gap before the next character in dots
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		if len(melody) > 0 {
+			gap = 7
+		}
+		for _, r := range word {
+			code, ok := morseCodes[r]
+			if !ok {
+				continue
+			}
+			if gap > 0 {
+				melody = append(melody, Note{Period: Silence, Duration: time.Duration(gap) * dot})
+			}
+			for i, symbol := range code {
+				if i > 0 {
+					melody = append(melody, Note{Period: Silence, Duration: dot})
+				}
+				length := dot
+				if symbol == '-' {
+					length = 3 * dot
+				}
+				melody = append(melody, Note{Period: m.Pitch, Duration: length})
+			}
+			gap = 3
+		}
+	}
+	return melody
+}
+
+// Voice renders text as speech-like beeps, in the style of cartoon robots. Every letter has
+// its own pitch: vowels glide up over a longer syllable while consonants are short blips,
+// so the same word always sounds the same.
+type Voice struct {
+	// Pitch is the lowest note of the voice.
+	Pitch NotePeriod
+	// Syllable is the duration of a vowel. Consonants last half as long.
+	Syllable time.Duration
+}
+
+// DefaultVoice speaks from C5 with 120ms syllables.
+var DefaultVoice = Voice{
+	Pitch:    C5,
+	Syllable: 120 * time.Millisecond,
+}
+
+// Speak returns the text as a melody of speech-like beeps. Spaces and punctuation become pauses,
+// and a question mark makes the pitch rise at the end. Other characters are skipped.
+// If Pitch or Syllable is zero, the value of DefaultVoice is used.
+func (v Voice) Speak(text string) []Note {
+	if v.Pitch == Silence {
+		v.Pitch = DefaultVoice.Pitch
+	}
+	if v.Syllable <= 0 {
+		v.Syllable = DefaultVoice.Syllable
+	}
+	// a short gap keeps consecutive beeps apart
+	gap := Note{Period: Silence, Duration: v.Syllable / 8}
+
+	var melody []Note
+	for _, r := range strings.ToLower(text) {
+		var offset int
+		switch {
+		case r >= 'a' && r <= 'z':
+			// scatter the letters over an octave, so neighbouring letters sound different
+			offset = int(r-'a') * 7 % 12
+		case r >= '0' && r <= '9':
+			offset = int(r - '0')
+		case r == '?':
+			melody = append(melody,
+				Note{Period: v.Pitch.Transpose(7), Duration: v.Syllable / 2}, gap,
+				Note{Period: v.Pitch.Transpose(12), Duration: v.Syllable}, gap)
+			continue
+		case unicode.IsSpace(r):
+			melody = append(melody, Note{Period: Silence, Duration: v.Syllable})
+			continue
+		case unicode.IsPunct(r):
+			melody = append(melody, Note{Period: Silence, Duration: 2 * v.Syllable})
+			continue
+		default:
+			continue
+		}
+
+		if strings.ContainsRune("aeiouy", r) {
+			for _, step := range []int{0, 2, 4} {
+				melody = append(melody, Note{Period: v.Pitch.Transpose(offset + step), Duration: v.Syllable / 3})
+			}
+		} else {
+			melody = append(melody, Note{Period: v.Pitch.Transpose(offset + 7), Duration: v.Syllable / 2})
+		}
+		melody = append(melody, gap)
+	}
+	return melody
+}
@@ -0,0 +1,210 @@
+package buzzer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NotePeriod represents the period of a note frequency in microseconds.
+type NotePeriod int
+
+// The notes of the chromatic scale from C3 to B7 in standard tuning (A4 = 440 Hz).
+// Sharps have an s suffix, so A4s is A#4. Flats are written as the sharp of the note below.
+const (
+	C3  NotePeriod = 7645 // 130.81 Hz
+	C3s NotePeriod = 7215 // 138.59 Hz
+	D3  NotePeriod = 6810 // 146.83 Hz
+	D3s NotePeriod = 6428 // 155.56 Hz
+	E3  NotePeriod = 6067 // 164.81 Hz
+	F3  NotePeriod = 5727 // 174.61 Hz
+	F3s NotePeriod = 5405 // 185.00 Hz
+	G3  NotePeriod = 5102 // 196.00 Hz
+	G3s NotePeriod = 4816 // 207.65 Hz
+	A3  NotePeriod = 4545 // 220.00 Hz
+	A3s NotePeriod = 4290 // 233.08 Hz
+	B3  NotePeriod = 4050 // 246.94 Hz
+
+	C4  NotePeriod = 3822 // 261.63 Hz
+	C4s NotePeriod = 3608 // 277.18 Hz
+	D4  NotePeriod = 3405 // 293.66 Hz
+	D4s NotePeriod = 3214 // 311.13 Hz
+	E4  NotePeriod = 3034 // 329.63 Hz
+	F4  NotePeriod = 2863 // 349.23 Hz
+	F4s NotePeriod = 2703 // 369.99 Hz
+	G4  NotePeriod = 2551 // 392.00 Hz
+	G4s NotePeriod = 2408 // 415.30 Hz
+	A4  NotePeriod = 2273 // 440.00 Hz
+	A4s NotePeriod = 2145 // 466.16 Hz
+	B4  NotePeriod = 2025 // 493.88 Hz
+
+	C5  NotePeriod = 1911 // 523.25 Hz
+	C5s NotePeriod = 1804 // 554.37 Hz
+	D5  NotePeriod = 1703 // 587.33 Hz
+	D5s NotePeriod = 1607 // 622.25 Hz
+	E5  NotePeriod = 1517 // 659.26 Hz
+	F5  NotePeriod = 1432 // 698.46 Hz
+	F5s NotePeriod = 1351 // 739.99 Hz
+	G5  NotePeriod = 1276 // 783.99 Hz
+	G5s NotePeriod = 1204 // 830.61 Hz
+	A5  NotePeriod = 1136 // 880.00 Hz
+	A5s NotePeriod = 1073 // 932.33 Hz
+	B5  NotePeriod = 1012 // 987.77 Hz
+
+	C6  NotePeriod = 956 // 1046.50 Hz
+	C6s NotePeriod = 902 // 1108.73 Hz
+	D6  NotePeriod = 851 // 1174.66 Hz
+	D6s NotePeriod = 804 // 1244.51 Hz
+	E6  NotePeriod = 758 // 1318.51 Hz
+	F6  NotePeriod = 716 // 1396.91 Hz
+	F6s NotePeriod = 676 // 1479.98 Hz
+	G6  NotePeriod = 638 // 1567.98 Hz
+	G6s NotePeriod = 602 // 1661.22 Hz
+	A6  NotePeriod = 568 // 1760.00 Hz
+	A6s NotePeriod = 536 // 1864.66 Hz
+	B6  NotePeriod = 506 // 1975.53 Hz
+
+	C7  NotePeriod = 478 // 2093.00 Hz
+	C7s NotePeriod = 451 // 2217.46 Hz
+	D7  NotePeriod = 426 // 2349.32 Hz
+	D7s NotePeriod = 402 // 2489.02 Hz
+	E7  NotePeriod = 379 // 2637.02 Hz
+	F7  NotePeriod = 358 // 2793.83 Hz
+	F7s NotePeriod = 338 // 2959.96 Hz
+	G7  NotePeriod = 319 // 3135.96 Hz
+	G7s NotePeriod = 301 // 3322.44 Hz
+	A7  NotePeriod = 284 // 3520.00 Hz
+	A7s NotePeriod = 268 // 3729.31 Hz
+	B7  NotePeriod = 253 // 3951.07 Hz
+
+	Silence NotePeriod = 0
+)
+
+// Tuning represents the reference pitch notes are derived from.
+type Tuning struct {
+	// A4 is the frequency of A4 in Hz.
+	A4 float64
+}
+
+// Standard is the standard tuning with A4 at 440 Hz, used by the note constants.
+var Standard = Tuning{A4: 440}
+
+// FromMIDI returns the period of the note with the given MIDI note number in this tuning,
+// where 60 is C4 and 69 is A4.
+func (t Tuning) FromMIDI(note int) NotePeriod {
+	return FromFrequency(t.A4 * math.Pow(2, float64(note-69)/12))
+}
+
+// FromMIDI returns the period of the note with the given MIDI note number in standard tuning,
+// where 60 is C4 and 69 is A4.
+func FromMIDI(note int) NotePeriod {
+	return Standard.FromMIDI(note)
+}
+
+// FromFrequency returns the period of the given frequency in Hz, rounded to the nearest microsecond.
+// It returns Silence if the frequency is not positive.
+func FromFrequency(hz float64) NotePeriod {
+	if hz <= 0 {
+		return Silence
+	}
+	return NotePeriod(math.Round(1e6 / hz))
+}
+
+// Frequency returns the frequency of the note in Hz, or zero for Silence.
+func (p NotePeriod) Frequency() float64 {
+	if p <= 0 {
+		return 0
+	}
+	return 1e6 / float64(p)
+}
+
+var ErrInvalidNote = errors.New("buzzer: invalid note name")
+
+// semitones maps the note letters to semitones above C. H is the German name of B.
+var semitones = map[byte]int{
+	'c': 0, 'd': 2, 'e': 4, 'f': 5, 'g': 7, 'a': 9, 'b': 11, 'h': 11,
+}
+
+// ParseNote parses a note name in scientific pitch notation, such as A4, C#5 or Bb3,
+// and returns its period in standard tuning.
+func ParseNote(name string) (NotePeriod, error) {
+	return Standard.ParseNote(name)
+}
+
+// ParseNote parses a note name in scientific pitch notation, such as A4, C#5 or Bb3,
+// and returns its period in this tuning.
+func (t Tuning) ParseNote(name string) (NotePeriod, error) {
+	note, err := midiNumber(name)
+	if err != nil {
+		return Silence, err
+	}
+	return t.FromMIDI(note), nil
+}
+
+// midiNumber returns the MIDI note number of the given note name.
+func midiNumber(name string) (int, error) {
+	s := strings.ToLower(strings.TrimSpace(name))
+	if s == "" {
+		return 0, fmt.Errorf("%w %q", ErrInvalidNote, name)
+	}
+	semitone, ok := semitones[s[0]]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrInvalidNote, name)
+	}
+	s = s[1:]
+	if len(s) > 0 {
+		switch s[0] {
+		case '#':
+			semitone++
+			s = s[1:]
+		case 'b':
+			semitone--
+			s = s[1:]
+		}
+	}
+	octave, err := strconv.Atoi(s)
+	if err != nil || octave < -1 || octave > 9 {
+		return 0, fmt.Errorf("%w %q", ErrInvalidNote, name)
+	}
+	return (octave+1)*12 + semitone, nil
+}
+
+// Transpose returns the note shifted by the given number of semitones, up if positive
+// and down if negative. Silence is returned unchanged.
+func (p NotePeriod) Transpose(semitones int) NotePeriod {
+	if p <= 0 || semitones == 0 {
+		return p
+	}
+	return FromFrequency(p.Frequency() * math.Pow(2, float64(semitones)/12))
+}
+
+// Transpose returns a copy of the melody with all notes, including the notes of chords,
+// shifted by the given number of semitones, for example 12 to play it an octave higher.
+func Transpose(melody []Note, semitones int) []Note {
+	out := make([]Note, len(melody))
+	for i, note := range melody {
+		note.Period = note.Period.Transpose(semitones)
+		for j, p := range note.Chord {
+			note.Chord[j] = p.Transpose(semitones)
+		}
+		out[i] = note
+	}
+	return out
+}
+
+// ScaleTempo returns a copy of the melody played the given times faster,
+// for example 2 halves the duration of every note and 0.5 doubles it.
+// A factor that is not positive returns an unchanged copy.
+func ScaleTempo(melody []Note, factor float64) []Note {
+	out := make([]Note, len(melody))
+	for i, note := range melody {
+		if factor > 0 {
+			note.Duration = time.Duration(float64(note.Duration) / factor)
+		}
+		out[i] = note
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package buzzer_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+)
+
+func TestParseNote(t *testing.T) {
+	tests := []struct {
+		name string
+		want buzzer.NotePeriod
+	}{
+		{"A4", buzzer.A4},
+		{"a4", buzzer.A4},
+		{" C4 ", buzzer.C4},
+		{"C#5", buzzer.C5s},
+		{"Db5", buzzer.C5s},
+		{"Bb3", buzzer.A3s},
+		{"H4", buzzer.B4},
+		{"Cb4", buzzer.B3},
+		{"B#3", buzzer.C4},
+		{"F#7", buzzer.F7s},
+		{"C-1", buzzer.FromMIDI(0)},
+		{"G9", buzzer.FromMIDI(127)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buzzer.ParseNote(tt.name)
+			if err != nil {
+				t.Fatalf("ParseNote(%q) error = %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseNote(%q) = %d, want %d", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNoteErrors(t *testing.T) {
+	for _, name := range []string{"", "  ", "X4", "A", "C#", "Ab", "A10", "C-2", "A4#", "4A"} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := buzzer.ParseNote(name); !errors.Is(err, buzzer.ErrInvalidNote) {
+				t.Errorf("ParseNote(%q) error = %v, want %v", name, err, buzzer.ErrInvalidNote)
+			}
+		})
+	}
+}
+
+func TestTuningParseNote(t *testing.T) {
+	tuning := buzzer.Tuning{A4: 432}
+	tests := []struct {
+		name string
+		want buzzer.NotePeriod
+	}{
+		{"A4", buzzer.FromFrequency(432)},
+		{"A5", buzzer.FromFrequency(864)},
+		{"A3", buzzer.FromFrequency(216)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tuning.ParseNote(tt.name)
+			if err != nil {
+				t.Fatalf("ParseNote(%q) error = %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseNote(%q) = %d, want %d", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// closeTo returns true if the melodies are equal, except for periods that differ by at most
+// a microsecond, since transposing starts from periods already rounded to microseconds.
+func closeTo(a, b []buzzer.Note) bool {
+	near := func(p, q buzzer.NotePeriod) bool {
+		return p-q <= 1 && q-p <= 1
+	}
+	return slices.EqualFunc(a, b, func(x, y buzzer.Note) bool {
+		return x.Duration == y.Duration && near(x.Period, y.Period) &&
+			near(x.Chord[0], y.Chord[0]) && near(x.Chord[1], y.Chord[1]) && near(x.Chord[2], y.Chord[2])
+	})
+}
+
+func TestTranspose(t *testing.T) {
+	melody := []buzzer.Note{
+		{Period: buzzer.C4, Duration: 100 * time.Millisecond},
+		{Period: buzzer.Silence, Duration: 50 * time.Millisecond},
+		{Duration: 200 * time.Millisecond, Chord: buzzer.Chord{buzzer.C4, buzzer.E4, buzzer.G4}},
+	}
+	original := slices.Clone(melody)
+
+	tests := []struct {
+		name      string
+		semitones int
+		want      []buzzer.Note
+	}{
+		{
+			name:      "octave up",
+			semitones: 12,
+			want: []buzzer.Note{
+				{Period: buzzer.C5, Duration: 100 * time.Millisecond},
+				{Period: buzzer.Silence, Duration: 50 * time.Millisecond},
+				{Duration: 200 * time.Millisecond, Chord: buzzer.Chord{buzzer.C5, buzzer.E5, buzzer.G5}},
+			},
+		},
+		{
+			name:      "fifth down",
+			semitones: -7,
+			want: []buzzer.Note{
+				{Period: buzzer.F3, Duration: 100 * time.Millisecond},
+				{Period: buzzer.Silence, Duration: 50 * time.Millisecond},
+				{Duration: 200 * time.Millisecond, Chord: buzzer.Chord{buzzer.F3, buzzer.A3, buzzer.C4}},
+			},
+		},
+		{
+			name:      "unchanged",
+			semitones: 0,
+			want:      original,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buzzer.Transpose(melody, tt.semitones)
+			if !closeTo(got, tt.want) {
+				t.Errorf("Transpose() = %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(melody, original) {
+				t.Errorf("Transpose() modified the melody to %v", melody)
+			}
+		})
+	}
+}
+
+func TestScaleTempo(t *testing.T) {
+	melody := []buzzer.Note{
+		{Period: buzzer.A4, Duration: 400 * time.Millisecond},
+		{Period: buzzer.Silence, Duration: 100 * time.Millisecond},
+	}
+	tests := []struct {
+		name   string
+		factor float64
+		want   []time.Duration
+	}{
+		{"faster", 2, []time.Duration{200 * time.Millisecond, 50 * time.Millisecond}},
+		{"slower", 0.5, []time.Duration{800 * time.Millisecond, 200 * time.Millisecond}},
+		{"zero factor", 0, []time.Duration{400 * time.Millisecond, 100 * time.Millisecond}},
+		{"negative factor", -1, []time.Duration{400 * time.Millisecond, 100 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buzzer.ScaleTempo(melody, tt.factor)
+			for i, note := range got {
+				if note.Period != melody[i].Period || note.Duration != tt.want[i] {
+					t.Errorf("ScaleTempo() note %d = %v, want %v for %v", i, note, tt.want[i], melody[i].Period)
+				}
+			}
+			if melody[0].Duration != 400*time.Millisecond {
+				t.Errorf("ScaleTempo() modified the melody to %v", melody)
+			}
+		})
+	}
+}
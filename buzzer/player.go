@@ -0,0 +1,173 @@
+package buzzer
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Player plays melodies on a Buzzer in the background, so the caller can keep
+// moving the robot or handling input while the music plays.
+// Only one melody is played at a time. While a Player is playing, the buzzer
+// should not be used directly.
+type Player struct {
+	b       *Buzzer
+	mu      sync.Mutex
+	current *Playback
+}
+
+// NewPlayer creates a new Player playing melodies on the given buzzer.
+func NewPlayer(b *Buzzer) *Player {
+	return &Player{b: b}
+}
+
+// Play starts playing the melody once and returns immediately.
+// If another melody is playing, it is stopped first.
+func (p *Player) Play(melody []Note) *Playback {
+	return p.play(melody, false)
+}
+
+// Loop starts playing the melody repeatedly until it is stopped and returns immediately.
+// If another melody is playing, it is stopped first. A melody without any note of positive
+// duration is played once, since looping it would never pause.
+func (p *Player) Loop(melody []Note) *Playback {
+	return p.play(melody, true)
+}
+
+// Stop stops the melody that is playing, if any, and waits for the buzzer to go silent.
+func (p *Player) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stop()
+}
+
+func (p *Player) stop() {
+	if p.current == nil {
+		return
+	}
+	p.current.Stop()
+	<-p.current.done
+	p.current = nil
+}
+
+func (p *Player) play(melody []Note, loop bool) *Playback {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pb := &Playback{
+		b:      p.b,
+		cancel: cancel,
+		pause:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	p.current = pb
+	go func() {
+		pb.finish(pb.run(ctx, melody, loop))
+	}()
+	return pb
+}
+
+// Playback represents a melody played by a Player.
+type Playback struct {
+	b      *Buzzer
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	paused bool
+	pause  chan struct{} // closed when paused
+	resume chan struct{} // closed when resumed
+	done   chan struct{}
+	err    error
+}
+
+// Done returns a channel that is closed when the melody is completed, stopped or fails.
+func (pb *Playback) Done() <-chan struct{} {
+	return pb.done
+}
+
+// Wait waits for the melody to complete and returns the error of the note that failed, if any.
+// If the melody was stopped it returns context.Canceled.
+func (pb *Playback) Wait() error {
+	<-pb.done
+	return pb.err
+}
+
+// Stop stops the melody. It returns immediately, use Wait to wait for the buzzer to go silent.
+func (pb *Playback) Stop() {
+	pb.cancel()
+}
+
+// Pause pauses the melody, silencing the buzzer in the middle of the current note.
+func (pb *Playback) Pause() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if pb.paused {
+		return
+	}
+	pb.paused = true
+	close(pb.pause)
+	pb.resume = make(chan struct{})
+}
+
+// Resume resumes a paused melody, playing the rest of the note on which it was paused.
+func (pb *Playback) Resume() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if !pb.paused {
+		return
+	}
+	pb.paused = false
+	close(pb.resume)
+	pb.pause = make(chan struct{})
+}
+
+// state returns whether the melody is paused, the channel closed when it is paused
+// and the channel closed when it is resumed.
+func (pb *Playback) state() (paused bool, pause, resume <-chan struct{}) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.paused, pb.pause, pb.resume
+}
+
+func (pb *Playback) finish(err error) {
+	pb.err = err
+	close(pb.done)
+}
+
+func (pb *Playback) run(ctx context.Context, melody []Note, loop bool) error {
+	// a melody without duration would loop without ever waiting
+	loop = loop && slices.ContainsFunc(melody, func(note Note) bool { return note.Duration > 0 })
+	for {
+		for _, note := range melody {
+			if err := pb.playNote(ctx, note); err != nil {
+				return err
+			}
+		}
+		if !loop {
+			return nil
+		}
+	}
+}
+
+// playNote plays the note for its duration, not counting the time it was paused.
+func (pb *Playback) playNote(ctx context.Context, note Note) error {
+	var offset time.Duration
+	for {
+		paused, pause, resume := pb.state()
+		if paused {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-resume:
+				continue
+			}
+		}
+		var err error
+		offset, err = pb.b.sound(ctx, note, offset, pause)
+		if err != nil || offset >= note.Duration {
+			return err
+		}
+	}
+}
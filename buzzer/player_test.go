@@ -0,0 +1,255 @@
+package buzzer_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/clock"
+)
+
+// steppedClock is a virtual clock whose timers only fire when the test advances it,
+// so the test controls how far the player gets. Each new timer sends its deadline on timers.
+type steppedClock struct {
+	*clock.Virtual
+	mu      sync.Mutex
+	pending []steppedTimer
+	timers  chan time.Time
+}
+
+type steppedTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newSteppedClock() *steppedClock {
+	return &steppedClock{
+		Virtual: clock.NewVirtual(time.Time{}),
+		timers:  make(chan time.Time, 16),
+	}
+}
+
+func (c *steppedClock) After(d time.Duration) <-chan time.Time {
+	t := steppedTimer{at: c.Now().Add(d), ch: make(chan time.Time, 1)}
+	c.mu.Lock()
+	c.pending = append(c.pending, t)
+	c.mu.Unlock()
+	c.timers <- t.at
+	return t.ch
+}
+
+func (c *steppedClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// advance advances the time and fires the timers that are due.
+func (c *steppedClock) advance(d time.Duration) {
+	c.Virtual.Advance(d)
+	now := c.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending := c.pending[:0]
+	for _, t := range c.pending {
+		if t.at.After(now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- now
+	}
+	c.pending = pending
+}
+
+// dutyPwm is a PWM channel that reports every duty cycle set on it.
+type dutyPwm struct {
+	duty chan uint32
+}
+
+func newDutyPwm() *dutyPwm {
+	return &dutyPwm{duty: make(chan uint32, 64)}
+}
+
+func (p *dutyPwm) Configure() error       { return nil }
+func (p *dutyPwm) Top() uint32            { return 1000 }
+func (p *dutyPwm) SetPeriod(uint64) error { return nil }
+func (p *dutyPwm) SetDuty(value uint32)   { p.duty <- value }
+
+// drain discards the duty cycles reported so far.
+func (p *dutyPwm) drain() {
+	for len(p.duty) > 0 {
+		<-p.duty
+	}
+}
+
+// waitSilent waits until the buzzer is silenced.
+func (p *dutyPwm) waitSilent(t *testing.T) {
+	t.Helper()
+	for {
+		select {
+		case d := <-p.duty:
+			if d == 0 {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("buzzer was not silenced")
+		}
+	}
+}
+
+func TestPlaybackPause(t *testing.T) {
+	melody := []buzzer.Note{
+		{Period: buzzer.A4, Duration: 100 * time.Millisecond},
+		{Period: buzzer.C4, Duration: 100 * time.Millisecond},
+	}
+	const length = 200 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		pauseAt  time.Duration // offset into the melody at which it is paused
+		pauseFor time.Duration // zero means no pause
+	}{
+		{"no pause", 0, 0},
+		{"pause in the first note", 40 * time.Millisecond, time.Second},
+		{"pause in the second note", 150 * time.Millisecond, 500 * time.Millisecond},
+		{"pause between notes", 100 * time.Millisecond, 300 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := newSteppedClock()
+			pwm := newDutyPwm()
+			p := buzzer.NewPlayer(buzzer.New(pwm, clk))
+			start := clk.Now()
+			pb := p.Play(melody)
+
+			var played time.Duration
+			paused := tt.pauseFor == 0
+			for done := false; !done; {
+				select {
+				case <-pb.Done():
+					done = true
+				case at := <-clk.timers:
+					remaining := at.Sub(clk.Now())
+					if !paused && played+remaining > tt.pauseAt {
+						paused = true
+						clk.advance(tt.pauseAt - played)
+						played = tt.pauseAt
+						pwm.drain()
+						pb.Pause()
+						pb.Pause()
+						pwm.waitSilent(t)
+						clk.advance(tt.pauseFor)
+						pb.Resume()
+						continue
+					}
+					clk.advance(remaining)
+					played += remaining
+				case <-time.After(time.Second):
+					t.Fatal("player did not wait for the next note")
+				}
+			}
+
+			if err := pb.Wait(); err != nil {
+				t.Fatalf("Wait() error = %v", err)
+			}
+			if played != length {
+				t.Errorf("melody played for %v, want %v", played, length)
+			}
+			if got, want := clk.Now().Sub(start), length+tt.pauseFor; got != want {
+				t.Errorf("melody took %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPlaybackStop(t *testing.T) {
+	tests := []struct {
+		name  string
+		start func(p *buzzer.Player, melody []buzzer.Note) *buzzer.Playback
+		stop  func(p *buzzer.Player, pb *buzzer.Playback)
+	}{
+		{
+			name:  "stop playback",
+			start: (*buzzer.Player).Play,
+			stop:  func(p *buzzer.Player, pb *buzzer.Playback) { pb.Stop() },
+		},
+		{
+			name:  "stop player",
+			start: (*buzzer.Player).Play,
+			stop:  func(p *buzzer.Player, pb *buzzer.Playback) { p.Stop() },
+		},
+		{
+			name:  "stop loop",
+			start: (*buzzer.Player).Loop,
+			stop:  func(p *buzzer.Player, pb *buzzer.Playback) { pb.Stop() },
+		},
+		{
+			name:  "stop while paused",
+			start: (*buzzer.Player).Play,
+			stop: func(p *buzzer.Player, pb *buzzer.Playback) {
+				pb.Pause()
+				pb.Stop()
+			},
+		},
+		{
+			name:  "play another melody",
+			start: (*buzzer.Player).Play,
+			stop:  func(p *buzzer.Player, pb *buzzer.Playback) { p.Play(nil).Wait() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := newSteppedClock()
+			pwm := newDutyPwm()
+			p := buzzer.NewPlayer(buzzer.New(pwm, clk))
+			pb := tt.start(p, []buzzer.Note{{Period: buzzer.A4, Duration: 100 * time.Millisecond}})
+			<-clk.timers
+			pwm.drain()
+
+			tt.stop(p, pb)
+			select {
+			case <-pb.Done():
+			case <-time.After(time.Second):
+				t.Fatal("melody did not stop")
+			}
+			if err := pb.Wait(); !errors.Is(err, context.Canceled) {
+				t.Errorf("Wait() error = %v, want %v", err, context.Canceled)
+			}
+			pwm.waitSilent(t)
+		})
+	}
+}
+
+func TestPlayerLoop(t *testing.T) {
+	clk := newSteppedClock()
+	p := buzzer.NewPlayer(buzzer.New(newDutyPwm(), clk))
+	pb := p.Loop([]buzzer.Note{{Period: buzzer.A4, Duration: 100 * time.Millisecond}})
+	for range 5 {
+		select {
+		case at := <-clk.timers:
+			clk.advance(at.Sub(clk.Now()))
+		case <-pb.Done():
+			t.Fatalf("loop ended with %v", pb.Wait())
+		}
+	}
+	<-clk.timers
+	pb.Stop()
+	if err := pb.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want %v", err, context.Canceled)
+	}
+	if got, want := clk.Now().Sub(time.Time{}), 500*time.Millisecond; got != want {
+		t.Errorf("loop played for %v, want %v", got, want)
+	}
+
+	// a loop without duration is played once instead of spinning
+	pb = p.Loop([]buzzer.Note{{Period: buzzer.A4}, {Period: buzzer.C4}})
+	select {
+	case <-pb.Done():
+		if err := pb.Wait(); err != nil {
+			t.Errorf("Wait() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("loop without duration did not end")
+	}
+}
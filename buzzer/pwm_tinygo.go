@@ -1,3 +1,5 @@
+//go:build tinygo
+
 // TinyGo implementation of PWM channel for buzzer functionality.
 package buzzer
 
@@ -0,0 +1,124 @@
+package buzzer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRTTTL = errors.New("buzzer: invalid RTTTL")
+
+// ParseRTTTL parses a melody in the RTTTL (Nokia ringtone) format, such as
+//
+//	Simpsons:d=4,o=5,b=160:c.6,e6,f#6,8a6,g.6,e6,c6,8a,8f#,8f#,8f#,2g
+//
+// It returns the name of the melody and its notes. The default duration (d), octave (o)
+// and tempo in beats per minute (b) are optional and default to 4, 6 and 63.
+// Notes can have a duration, a sharp (#), a dot that makes them 50% longer and an octave.
+// Pauses (p) are returned as Silence.
+func ParseRTTTL(s string) (string, []Note, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return "", nil, fmt.Errorf("%w: expected name, defaults and notes separated by ':'", ErrInvalidRTTTL)
+	}
+	name := strings.TrimSpace(parts[0])
+
+	duration, octave, bpm := 4, 6, 63
+	for _, def := range strings.Split(parts[1], ",") {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(def, "=")
+		v, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil || v <= 0 {
+			return "", nil, fmt.Errorf("%w: invalid default %q", ErrInvalidRTTTL, def)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "d":
+			duration = v
+		case "o":
+			octave = v
+		case "b":
+			bpm = v
+		default:
+			return "", nil, fmt.Errorf("%w: invalid default %q", ErrInvalidRTTTL, def)
+		}
+	}
+
+	// duration of a whole note, a beat is a quarter note
+	whole := 4 * time.Minute / time.Duration(bpm)
+
+	var melody []Note
+	for _, tok := range strings.Split(parts[2], ",") {
+		tok = strings.ToLower(strings.TrimSpace(tok))
+		if tok == "" {
+			continue
+		}
+		note, err := parseRTTTLNote(tok, duration, octave, whole)
+		if err != nil {
+			return "", nil, err
+		}
+		melody = append(melody, note)
+	}
+	return name, melody, nil
+}
+
+// parseRTTTLNote parses a single RTTTL note, such as 8c#.6
+func parseRTTTLNote(tok string, duration, octave int, whole time.Duration) (Note, error) {
+	i := 0
+	// digits parses the number at i, returning false if there is none
+	digits := func() (int, bool) {
+		start := i
+		for i < len(tok) && tok[i] >= '0' && tok[i] <= '9' {
+			i++
+		}
+		v, err := strconv.Atoi(tok[start:i])
+		return v, err == nil
+	}
+
+	if d, ok := digits(); ok {
+		duration = d
+	}
+	if i >= len(tok) || duration <= 0 {
+		return Note{}, fmt.Errorf("%w: invalid note %q", ErrInvalidRTTTL, tok)
+	}
+
+	letter := tok[i]
+	i++
+	semitone, ok := semitones[letter]
+	if !ok && letter != 'p' {
+		return Note{}, fmt.Errorf("%w: invalid note %q", ErrInvalidRTTTL, tok)
+	}
+	if i < len(tok) && tok[i] == '#' {
+		semitone++
+		i++
+	}
+	// the dot is allowed both before and after the octave
+	dotted := false
+	if i < len(tok) && tok[i] == '.' {
+		dotted = true
+		i++
+	}
+	if o, ok := digits(); ok {
+		octave = o
+	}
+	if i < len(tok) && tok[i] == '.' {
+		dotted = true
+		i++
+	}
+	if i != len(tok) {
+		return Note{}, fmt.Errorf("%w: invalid note %q", ErrInvalidRTTTL, tok)
+	}
+
+	note := Note{Duration: whole / time.Duration(duration)}
+	if dotted {
+		note.Duration += note.Duration / 2
+	}
+	if letter != 'p' {
+		note.Period = FromMIDI((octave+1)*12 + semitone)
+	}
+	return note, nil
+}
@@ -0,0 +1,102 @@
+package buzzer_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+)
+
+func TestParseRTTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		rtttl    string
+		wantName string
+		want     []buzzer.Note
+	}{
+		{
+			name:     "durations, sharps, dots and octaves",
+			rtttl:    "Test:d=4,o=5,b=120:c,8d#6,4e.,p,2p.,16f#.,a7",
+			wantName: "Test",
+			want: []buzzer.Note{
+				{Period: buzzer.FromMIDI(72), Duration: 500 * time.Millisecond},
+				{Period: buzzer.FromMIDI(87), Duration: 250 * time.Millisecond},
+				{Period: buzzer.FromMIDI(76), Duration: 750 * time.Millisecond},
+				{Period: buzzer.Silence, Duration: 500 * time.Millisecond},
+				{Period: buzzer.Silence, Duration: 1500 * time.Millisecond},
+				{Period: buzzer.FromMIDI(78), Duration: 187500 * time.Microsecond},
+				{Period: buzzer.FromMIDI(105), Duration: 500 * time.Millisecond},
+			},
+		},
+		{
+			name:  "dot after the octave",
+			rtttl: ":b=120:c6.",
+			want: []buzzer.Note{
+				{Period: buzzer.FromMIDI(84), Duration: 750 * time.Millisecond},
+			},
+		},
+		{
+			name:     "default duration, octave and tempo",
+			rtttl:    "Defaults::c,1a",
+			wantName: "Defaults",
+			want: []buzzer.Note{
+				{Period: buzzer.FromMIDI(84), Duration: time.Minute / 63},
+				{Period: buzzer.FromMIDI(93), Duration: 4 * time.Minute / 63},
+			},
+		},
+		{
+			name:     "spaces and upper case",
+			rtttl:    " Spaced : D=8 , O=4 , B=240 : C , A , ",
+			wantName: "Spaced",
+			want: []buzzer.Note{
+				{Period: buzzer.C4, Duration: 125 * time.Millisecond},
+				{Period: buzzer.A4, Duration: 125 * time.Millisecond},
+			},
+		},
+		{
+			name:     "no notes",
+			rtttl:    "Empty:d=4:",
+			wantName: "Empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, got, err := buzzer.ParseRTTTL(tt.rtttl)
+			if err != nil {
+				t.Fatalf("ParseRTTTL() error = %v", err)
+			}
+			if name != tt.wantName {
+				t.Errorf("ParseRTTTL() name = %q, want %q", name, tt.wantName)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseRTTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRTTTLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rtttl string
+	}{
+		{"missing sections", "Test:c,d,e"},
+		{"zero default", "Test:d=0:c"},
+		{"unknown default", "Test:q=4:c"},
+		{"default without value", "Test:d:c"},
+		{"invalid default value", "Test:b=fast:c"},
+		{"unknown note", "Test::z"},
+		{"duration without note", "Test::8"},
+		{"zero duration", "Test::0c"},
+		{"trailing characters", "Test::c#x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := buzzer.ParseRTTTL(tt.rtttl); !errors.Is(err, buzzer.ErrInvalidRTTTL) {
+				t.Errorf("ParseRTTTL() error = %v, want %v", err, buzzer.ErrInvalidRTTTL)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package buzzer
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// MaxVolume is the loudest volume, at which notes are played with a 50% duty cycle.
+const MaxVolume = 100
+
+// DefaultArpeggioRate is the default number of chord notes played per second.
+const DefaultArpeggioRate = 50
+
+// MaxArpeggioRate is the highest number of chord notes played per second, one per millisecond.
+const MaxArpeggioRate = 1000
+
+// timbreStep is the interval at which the duty cycle and period are updated
+// while playing a note with an envelope or vibrato.
+const timbreStep = 5 * time.Millisecond
+
+// Timbre shapes the sound of the notes played on a buzzer.
+// The zero value plays plain notes at a constant volume and pitch.
+type Timbre struct {
+	// Attack is the time over which a note fades in from silence.
+	Attack time.Duration
+	// Decay is the time at the end of a note over which it fades out to silence.
+	Decay time.Duration
+	// Vibrato modulates the pitch during the note.
+	Vibrato Vibrato
+}
+
+// Vibrato describes a periodic modulation of the pitch of a note.
+type Vibrato struct {
+	// Rate is the number of modulation cycles per second.
+	Rate float64
+	// Depth is the maximum deviation of the pitch in semitones, for example 0.5 for a quarter tone.
+	Depth float64
+}
+
+// shaped returns true if the timbre changes the sound during the note.
+func (t Timbre) shaped() bool {
+	return t.Attack > 0 || t.Decay > 0 || (t.Vibrato.Rate > 0 && t.Vibrato.Depth != 0)
+}
+
+// level returns the volume (0-1) at the given time of a note with the given duration.
+func (t Timbre) level(at, duration time.Duration) float64 {
+	level := 1.0
+	if t.Attack > 0 {
+		level = min(level, float64(at)/float64(t.Attack))
+	}
+	if t.Decay > 0 {
+		level = min(level, float64(duration-at)/float64(t.Decay))
+	}
+	return max(level, 0)
+}
+
+// period returns the period of the note at the given time.
+func (t Timbre) period(period NotePeriod, at time.Duration) NotePeriod {
+	if t.Vibrato.Rate <= 0 || t.Vibrato.Depth == 0 {
+		return period
+	}
+	shift := t.Vibrato.Depth * math.Sin(2*math.Pi*t.Vibrato.Rate*at.Seconds())
+	return NotePeriod(math.Round(float64(period) / math.Pow(2, shift/12)))
+}
+
+// SetVolume sets the volume of the buzzer from 0 (silent) to MaxVolume, which is the default.
+// The volume scales the duty cycle of the PWM channel, so low volumes also soften the tone.
+func (b *Buzzer) SetVolume(volume int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.volume = min(max(volume, 0), MaxVolume)
+}
+
+// SetTimbre sets the timbre used for all the notes played on the buzzer.
+func (b *Buzzer) SetTimbre(t Timbre) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.timbre = t
+}
+
+// SetArpeggioRate sets the number of notes per second played when arpeggiating a chord.
+// Fast rates sound closer to a chord, slow rates to a broken chord. Rates that are not
+// positive reset it to DefaultArpeggioRate, and rates above MaxArpeggioRate are limited to it.
+func (b *Buzzer) SetArpeggioRate(rate int) {
+	if rate <= 0 {
+		rate = DefaultArpeggioRate
+	}
+	rate = min(rate, MaxArpeggioRate)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.arp = time.Second / time.Duration(rate)
+}
+
+// duty returns the duty cycle for the given volume level (0-1).
+func (b *Buzzer) duty(volume int, level float64) uint32 {
+	return uint32(float64(b.ch.Top()/2) * float64(volume) / MaxVolume * level)
+}
+
+// sound plays the note starting from the given offset into it, until the note ends, ctx is cancelled
+// or stop is closed. It returns the offset the note reached, which is its duration if it ended.
+func (b *Buzzer) sound(ctx context.Context, note Note, offset time.Duration, stop <-chan struct{}) (time.Duration, error) {
+	b.mu.Lock()
+	volume, timbre, arp := b.volume, b.timbre, b.arp
+	b.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return offset, err
+	}
+	defer b.ch.SetDuty(0)
+
+	start := b.clock.Now().Add(-offset)
+	pitches := note.pitches()
+	silent := len(pitches) == 0 || volume == 0
+	shaped := timbre.shaped() && !silent
+	chord := len(pitches) > 1
+	var period NotePeriod
+	for {
+		at := b.clock.Now().Sub(start)
+		if at >= note.Duration {
+			return note.Duration, nil
+		}
+
+		step := note.Duration - at
+		if silent {
+			b.ch.SetDuty(0)
+		} else {
+			base := pitches[0]
+			if shaped {
+				step = min(step, timbreStep)
+			}
+			if chord {
+				// switch to the next note of the chord at the end of the arpeggio step
+				base = pitches[int(at/arp)%len(pitches)]
+				step = min(step, arp-at%arp)
+			}
+			if p := timbre.period(base, at); p != period {
+				if err := b.ch.SetPeriod(uint64(p * 1000)); err != nil {
+					return at, err
+				}
+				period = p
+			}
+			b.ch.SetDuty(b.duty(volume, timbre.level(at, note.Duration)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return b.clock.Now().Sub(start), ctx.Err()
+		case <-stop:
+			return b.clock.Now().Sub(start), nil
+		case <-b.clock.After(step):
+		}
+	}
+}
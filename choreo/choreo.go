@@ -0,0 +1,445 @@
+// Package choreo implements a small human-readable text format for robot routines.
+//
+// A routine has one command per line. Empty lines are ignored, and # starts a comment
+// that runs to the end of the line. Durations use Go syntax, such as 250ms or 1.5s.
+// The commands are:
+//
+//	mode walk|roll                switch the robot mode
+//	home                          move to the home position
+//	tilt left|right|center        tilt, or return from a tilt
+//	walk STEPS                    walk forward, or backward for negative steps
+//	foot left|right SPEED for DUR spin a foot
+//	spin left|right SPEED for DUR tilt and spin on a leg
+//	legs LEFT RIGHT               move both legs to the given angles
+//	roll THROTTLE TURN [for DUR]  roll, stopping after the duration if given
+//	wave                          wave with the left leg
+//	note NAME DUR                 play a note, such as A4, C#5 or Bb3, or REST
+//	wait DUR                      keep still for the duration
+//	repeat COUNT {                repeat the commands up to the closing }
+//	}
+//
+// The braces of a repeat do not need to be on their own lines, so a short loop can be
+// written on a single line, such as repeat 3 { wave }. Commands following an opening brace
+// or around a closing brace on the same line are parsed as if they were on their own lines.
+package choreo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+var (
+	ErrUnknownCommand = errors.New("choreo: unknown command")
+	ErrArguments      = errors.New("choreo: wrong number of arguments")
+	ErrInvalidValue   = errors.New("choreo: invalid value")
+	ErrUnclosedRepeat = errors.New("choreo: repeat is not closed")
+	ErrUnexpectedEnd  = errors.New("choreo: unexpected }")
+	ErrUnknownNote    = errors.New("choreo: unknown note")
+)
+
+// Error represents an error in a routine, either when parsing or running it.
+type Error struct {
+	// Line is the line of the routine on which the error occurred, starting at 1.
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v (line %d)", e.Err, e.Line)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// runFunc runs a compiled command.
+type runFunc func(ctx context.Context, n *ninja.Ninja, bz *buzzer.Buzzer) error
+
+// command is a single compiled command of a routine.
+type command struct {
+	line int
+	run  runFunc
+}
+
+// Program is a compiled routine.
+type Program struct {
+	commands []command
+}
+
+// Run runs the program on the given Ninja, playing notes on the given buzzer.
+// The buzzer can be nil if the program does not play notes.
+// If a command fails or ctx is cancelled, Run stops and returns an *Error with the
+// line of the command.
+func (p *Program) Run(ctx context.Context, n *ninja.Ninja, bz *buzzer.Buzzer) error {
+	return run(ctx, p.commands, n, bz)
+}
+
+func run(ctx context.Context, commands []command, n *ninja.Ninja, bz *buzzer.Buzzer) error {
+	for _, c := range commands {
+		err := c.run(ctx, n, bz)
+		if err == nil {
+			continue
+		}
+		if _, ok := err.(*Error); ok {
+			return err
+		}
+		return &Error{Line: c.line, Err: err}
+	}
+	return nil
+}
+
+// ParseString parses the routine in the given string.
+func ParseString(s string) (*Program, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// Parse parses the routine read from r.
+// It returns an *Error with the line of the first syntax error.
+func Parse(r io.Reader) (*Program, error) {
+	p := parser{scanner: bufio.NewScanner(r)}
+	commands, err := p.parseBlock(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &Program{commands: commands}, nil
+}
+
+type parser struct {
+	scanner *bufio.Scanner
+	line    int
+	pending [][]string // statements left on the current line
+}
+
+// next returns the fields of the next statement, reading a new line when the current one is done.
+func (p *parser) next() ([]string, bool) {
+	for len(p.pending) == 0 {
+		if !p.scanner.Scan() {
+			return nil, false
+		}
+		p.line++
+		p.pending = statements(p.scanner.Text())
+	}
+	fields := p.pending[0]
+	p.pending = p.pending[1:]
+	return fields, true
+}
+
+// statements splits a line into the fields of its statements. An opening brace ends
+// the statement before it, while a closing brace is a statement of its own.
+func statements(line string) [][]string {
+	line = strings.NewReplacer("{", " { ", "}", " } ").Replace(line)
+	var (
+		stmts  [][]string
+		fields []string
+	)
+	for _, f := range strings.Fields(line) {
+		if strings.HasPrefix(f, "#") {
+			break
+		}
+		switch f {
+		case "{":
+			stmts = append(stmts, append(fields, f))
+			fields = nil
+		case "}":
+			if len(fields) > 0 {
+				stmts = append(stmts, fields)
+			}
+			stmts = append(stmts, []string{f})
+			fields = nil
+		default:
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) > 0 {
+		stmts = append(stmts, fields)
+	}
+	return stmts
+}
+
+// parseBlock parses commands until the end of input, or until the closing } of a repeat
+// started on the given line. Line zero means the top level.
+func (p *parser) parseBlock(repeatLine int) ([]command, error) {
+	var commands []command
+	for {
+		fields, ok := p.next()
+		if !ok {
+			break
+		}
+
+		name := strings.ToLower(fields[0])
+		switch {
+		case name == "}":
+			if repeatLine == 0 {
+				return nil, &Error{Line: p.line, Err: ErrUnexpectedEnd}
+			}
+			return commands, nil
+		case name == "repeat":
+			c, err := p.parseRepeat(fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			commands = append(commands, c)
+		default:
+			fn, err := parseCommand(name, fields[1:])
+			if err != nil {
+				return nil, &Error{Line: p.line, Err: err}
+			}
+			commands = append(commands, command{line: p.line, run: fn})
+		}
+	}
+	if repeatLine != 0 {
+		return nil, &Error{Line: repeatLine, Err: ErrUnclosedRepeat}
+	}
+	return commands, nil
+}
+
+func (p *parser) parseRepeat(args []string) (command, error) {
+	line := p.line
+	if len(args) != 2 || args[1] != "{" {
+		return command{}, &Error{Line: line, Err: ErrArguments}
+	}
+	count, err := strconv.Atoi(args[0])
+	if err != nil || count < 0 {
+		return command{}, &Error{Line: line, Err: fmt.Errorf("%w: repeat count %q", ErrInvalidValue, args[0])}
+	}
+	body, err := p.parseBlock(line)
+	if err != nil {
+		return command{}, err
+	}
+	return command{
+		line: line,
+		run: func(ctx context.Context, n *ninja.Ninja, bz *buzzer.Buzzer) error {
+			for range count {
+				if err := run(ctx, body, n, bz); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// parseCommand parses a single command with its arguments.
+func parseCommand(name string, args []string) (runFunc, error) {
+	switch name {
+	case "mode":
+		if len(args) != 1 {
+			return nil, ErrArguments
+		}
+		var mode ninja.Mode
+		switch strings.ToLower(args[0]) {
+		case "walk":
+			mode = ninja.ModeWalk
+		case "roll":
+			mode = ninja.ModeRoll
+		default:
+			return nil, fmt.Errorf("%w: mode %q", ErrInvalidValue, args[0])
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			return n.ModeContext(ctx, mode)
+		}, nil
+
+	case "home":
+		if len(args) != 0 {
+			return nil, ErrArguments
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			return n.HomeContext(ctx)
+		}, nil
+
+	case "tilt":
+		if len(args) != 1 {
+			return nil, ErrArguments
+		}
+		var dir ninja.TiltDir
+		switch strings.ToLower(args[0]) {
+		case "left":
+			dir = ninja.TiltLeft
+		case "right":
+			dir = ninja.TiltRight
+		case "center":
+			dir = ninja.TiltReturnFromLeft
+		default:
+			return nil, fmt.Errorf("%w: tilt direction %q", ErrInvalidValue, args[0])
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			return n.TiltContext(ctx, dir)
+		}, nil
+
+	case "walk":
+		if len(args) != 1 {
+			return nil, ErrArguments
+		}
+		steps, err := parseInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			return n.WalkContext(ctx, steps)
+		}, nil
+
+	case "foot", "spin":
+		if len(args) != 4 || strings.ToLower(args[2]) != "for" {
+			return nil, ErrArguments
+		}
+		speed, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		duration, err := parseDuration(args[3])
+		if err != nil {
+			return nil, err
+		}
+		left, err := parseSide(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			switch {
+			case name == "foot" && left:
+				return n.MoveLeftFootContext(ctx, speed, duration)
+			case name == "foot":
+				return n.MoveRightFootContext(ctx, speed, duration)
+			case left:
+				return n.LeftLegSpinContext(ctx, speed, duration)
+			default:
+				return n.RightLegSpinContext(ctx, speed, duration)
+			}
+		}, nil
+
+	case "legs":
+		if len(args) != 2 {
+			return nil, ErrArguments
+		}
+		left, err := parseInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		right, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			return n.MoveLegsContext(ctx, left, right)
+		}, nil
+
+	case "roll":
+		if len(args) != 2 && (len(args) != 4 || strings.ToLower(args[2]) != "for") {
+			return nil, ErrArguments
+		}
+		throttle, err := parseInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		turn, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		var duration time.Duration
+		if len(args) == 4 {
+			if duration, err = parseDuration(args[3]); err != nil {
+				return nil, err
+			}
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			if err := n.Roll(throttle, turn); err != nil || duration == 0 {
+				return err
+			}
+			if err := n.PauseContext(ctx, duration); err != nil {
+				return err
+			}
+			return n.RollStop()
+		}, nil
+
+	case "wave":
+		if len(args) != 0 {
+			return nil, ErrArguments
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			return n.WaveContext(ctx)
+		}, nil
+
+	case "note":
+		if len(args) != 2 {
+			return nil, ErrArguments
+		}
+		period, err := parseNote(args[0])
+		if err != nil {
+			return nil, err
+		}
+		duration, err := parseDuration(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, _ *ninja.Ninja, bz *buzzer.Buzzer) error {
+			if bz == nil {
+				return ninja.ErrBuzzerNotConfigured
+			}
+			return bz.ToneContext(ctx, buzzer.Note{Period: period, Duration: duration})
+		}, nil
+
+	case "wait":
+		if len(args) != 1 {
+			return nil, ErrArguments
+		}
+		duration, err := parseDuration(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, n *ninja.Ninja, _ *buzzer.Buzzer) error {
+			return n.PauseContext(ctx, duration)
+		}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownCommand, name)
+}
+
+// parseNote parses a note name, such as A4, C#5 or Bb3, or REST for silence.
+func parseNote(s string) (buzzer.NotePeriod, error) {
+	if strings.EqualFold(s, "rest") {
+		return buzzer.Silence, nil
+	}
+	period, err := buzzer.ParseNote(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrUnknownNote, s)
+	}
+	return period, nil
+}
+
+func parseInt(s string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: number %q", ErrInvalidValue, s)
+	}
+	return v, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: duration %q", ErrInvalidValue, s)
+	}
+	return d, nil
+}
+
+// parseSide returns true for left and false for right.
+func parseSide(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "left":
+		return true, nil
+	case "right":
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: side %q", ErrInvalidValue, s)
+}
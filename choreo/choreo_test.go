@@ -0,0 +1,162 @@
+package choreo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/choreo"
+	"github.com/HattoriHanzo031/gotto/clock"
+	"github.com/HattoriHanzo031/gotto/ninja"
+	"github.com/HattoriHanzo031/gotto/sim"
+)
+
+// run runs the routine on a simulated robot in virtual time and returns how long it took.
+func run(ctx context.Context, src string, withBuzzer bool) (time.Duration, error) {
+	p, err := choreo.ParseString(src)
+	if err != nil {
+		return 0, err
+	}
+	clk := clock.NewVirtual(time.Time{})
+	var bz *buzzer.Buzzer
+	if withBuzzer {
+		bz = buzzer.New(sim.NewPiezo(clk, 8000), clk)
+	}
+	n := sim.NewRobot(clk).Ninja(bz)
+	start := clk.Now()
+	err = p.Run(ctx, n, bz)
+	return clk.Now().Sub(start), err
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want time.Duration
+	}{
+		{
+			name: "braces on their own lines",
+			src:  "repeat 3 {\n\twait 100ms\n}\n",
+			want: 300 * time.Millisecond,
+		},
+		{
+			name: "inline repeat",
+			src:  "repeat 3 { wait 100ms }",
+			want: 300 * time.Millisecond,
+		},
+		{
+			name: "opening brace without space",
+			src:  "repeat 3{\nwait 100ms\n}",
+			want: 300 * time.Millisecond,
+		},
+		{
+			name: "trailing closing brace",
+			src:  "repeat 3 {\nwait 100ms }",
+			want: 300 * time.Millisecond,
+		},
+		{
+			name: "several commands in an inline repeat",
+			src:  "repeat 2 { wait 10ms\nwait 20ms }",
+			want: 60 * time.Millisecond,
+		},
+		{
+			name: "nested inline repeats",
+			src:  "repeat 2 { repeat 3 { wait 10ms } wait 100ms }",
+			want: 260 * time.Millisecond,
+		},
+		{
+			name: "zero repeats",
+			src:  "repeat 0 { wait 1s }\nwait 10ms",
+			want: 10 * time.Millisecond,
+		},
+		{
+			name: "comments and empty lines",
+			src:  "# intro\n\nwait 50ms # pause\n   \n",
+			want: 50 * time.Millisecond,
+		},
+		{
+			name: "notes",
+			src:  "note A4 100ms\nnote C#5 20ms\nnote Bb3 30ms\nnote REST 50ms",
+			want: 200 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := run(context.Background(), tt.src, true)
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("run() took %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want error
+		line int
+	}{
+		{"unknown command", "wait 1s\ndance", choreo.ErrUnknownCommand, 2},
+		{"missing argument", "wait", choreo.ErrArguments, 1},
+		{"extra argument", "home now", choreo.ErrArguments, 1},
+		{"negative duration", "wait -1s", choreo.ErrInvalidValue, 1},
+		{"invalid mode", "mode fly", choreo.ErrInvalidValue, 1},
+		{"invalid number", "walk far", choreo.ErrInvalidValue, 1},
+		{"foot without for", "foot left 50 1s", choreo.ErrArguments, 1},
+		{"unknown note", "note X4 1s", choreo.ErrUnknownNote, 1},
+		{"unexpected closing brace", "wait 1s\n}", choreo.ErrUnexpectedEnd, 2},
+		{"extra inline closing brace", "repeat 2 { wave } }", choreo.ErrUnexpectedEnd, 1},
+		{"unclosed repeat", "\nrepeat 2 {\nwait 1s", choreo.ErrUnclosedRepeat, 2},
+		{"repeat without brace", "repeat 2 wave", choreo.ErrArguments, 1},
+		{"invalid repeat count", "repeat x { wave }", choreo.ErrInvalidValue, 1},
+		{"error inside inline repeat", "repeat 2 {\nwait 1s } repeat 2 { walk }", choreo.ErrArguments, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := choreo.ParseString(tt.src)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ParseString() error = %v, want %v", err, tt.want)
+			}
+			var e *choreo.Error
+			if !errors.As(err, &e) || e.Line != tt.line {
+				t.Errorf("ParseString() error = %v, want line %d", err, tt.line)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		src  string
+		want error
+		line int
+	}{
+		{"wave in roll mode", context.Background(), "mode roll\nwave", ninja.ErrInvalidMode, 2},
+		{"note without buzzer", context.Background(), "wait 1s\nnote A4 1s", ninja.ErrBuzzerNotConfigured, 2},
+		{"error inside repeat", context.Background(), "mode roll\nrepeat 2 {\n  wait 1s\n  tilt left\n}", ninja.ErrInvalidMode, 4},
+		{"cancelled", cancelled, "wait 1s", context.Canceled, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(tt.ctx, tt.src, false)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.want)
+			}
+			var e *choreo.Error
+			if !errors.As(err, &e) || e.Line != tt.line {
+				t.Errorf("Run() error = %v, want line %d", err, tt.line)
+			}
+		})
+	}
+}
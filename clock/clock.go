@@ -0,0 +1,87 @@
+// Package clock provides an abstraction of time used by the robot motions and the buzzer.
+// The System clock uses real time, while the Virtual clock advances instantly,
+// allowing long routines to be simulated on the host in milliseconds.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is an interface that abstracts reading the current time and sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep pauses the caller for the given duration.
+	Sleep(d time.Duration)
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// System is the Clock backed by the system time.
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+// Now returns the current system time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses the caller for the given duration.
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// After waits for the duration to elapse and then sends the current time on the returned channel.
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Virtual is a Clock that does not follow the system time.
+// Sleeping on a virtual clock returns immediately and advances its time by the slept duration.
+// Virtual clock is meant to be used by a single goroutine at a time, since concurrent sleeps
+// advance the same time one after the other.
+type Virtual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewVirtual creates a new Virtual clock starting at the given time.
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{
+		now: start,
+	}
+}
+
+// Now returns the current virtual time.
+func (v *Virtual) Now() time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.now
+}
+
+// Sleep advances the virtual time by the given duration and returns immediately.
+func (v *Virtual) Sleep(d time.Duration) {
+	v.Advance(d)
+}
+
+// After advances the virtual time by the given duration and returns a channel
+// that already holds the new time.
+func (v *Virtual) After(d time.Duration) <-chan time.Time {
+	v.Advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- v.Now()
+	return ch
+}
+
+// Advance advances the virtual time by the given duration.
+// Negative durations are ignored.
+func (v *Virtual) Advance(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.now = v.now.Add(d)
+}
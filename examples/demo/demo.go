@@ -7,6 +7,7 @@ import (
 	"github.com/HattoriHanzo031/gotto/buzzer"
 	"github.com/HattoriHanzo031/gotto/ninja"
 	"github.com/HattoriHanzo031/gotto/servo"
+	"github.com/HattoriHanzo031/gotto/settings"
 	tgservo "tinygo.org/x/drivers/servo"
 )
 
@@ -37,22 +38,18 @@ func main() {
 	lfServo := servo.New360(must(footArr.Add(lFoot)), 450, 2550)
 	rfServo := servo.New360(must(footArr.Add(rFoot)), 450, 2550)
 
-	bz := buzzer.New(buzzer.NewPwmChannel(pwmBuzzer, buzzerPin))
+	bz := buzzer.New(buzzer.NewPwmChannel(pwmBuzzer, buzzerPin), nil)
 	if err := bz.Configure(); err != nil {
 		panic(err)
 	}
 
-	n := ninja.New(rlServo, llServo, rfServo, lfServo, bz)
-
-	n.Trim(ninja.Trim{
-		TiltAngle:         0,
-		LeftStepDuration:  0,
-		RightStepDuration: 150 * time.Millisecond,
-		LfSpeed:           0,
-		RfSpeed:           0,
-		LlAngle:           20,
-		RlAngle:           12,
-	})
+	n := ninja.New(rlServo, llServo, rfServo, lfServo, bz, nil)
+
+	trim, err := settings.NewFlashStore(machine.Flash, 0).LoadTrim()
+	if err != nil {
+		println("Using default trim:", err.Error())
+	}
+	n.Trim(trim)
 
 	for {
 		n.Mode(ninja.ModeWalk)
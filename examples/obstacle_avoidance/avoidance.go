@@ -8,6 +8,7 @@ import (
 	"github.com/HattoriHanzo031/gotto/buzzer"
 	"github.com/HattoriHanzo031/gotto/ninja"
 	"github.com/HattoriHanzo031/gotto/servo"
+	"github.com/HattoriHanzo031/gotto/settings"
 	"tinygo.org/x/drivers/hcsr04"
 	tgservo "tinygo.org/x/drivers/servo"
 )
@@ -39,23 +40,17 @@ func main() {
 	lfServo := servo.New360(must(footArr.Add(lFootPin)), 450, 2550)
 	rfServo := servo.New360(must(footArr.Add(rFootPin)), 450, 2550)
 
-	bz := buzzer.New(buzzer.NewPwmChannel(buzzerPwm, buzzerPin))
+	bz := buzzer.New(buzzer.NewPwmChannel(buzzerPwm, buzzerPin), nil)
 	err := bz.Configure()
 	if err != nil {
 		panic(err)
 	}
 
-	n := ninja.New(rlServo, llServo, rfServo, lfServo, bz)
-	trim := ninja.Trim{
-		TiltAngle:         0,
-		LeftStepDuration:  0,
-		RightStepDuration: 150 * time.Millisecond,
-		LfSpeed:           0,
-		RfSpeed:           0,
-		LlAngle:           20,
-		RlAngle:           12,
+	n := ninja.New(rlServo, llServo, rfServo, lfServo, bz, nil)
+	trim, err := settings.NewFlashStore(machine.Flash, 0).LoadTrim()
+	if err != nil {
+		println("Using default trim:", err.Error())
 	}
-
 	n.Trim(trim)
 
 	us := hcsr04.New(usTrigPin, usEchoPin)
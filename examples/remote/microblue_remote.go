@@ -21,7 +21,9 @@ func NewMicroBlue() *microBlue {
 	}
 }
 
-func (r *microBlue) ReadCommand() remote.Command {
+// ReadCommand reads the next command from the remote.
+// It returns false for input that does not map to a command, such as releasing a melody button.
+func (r *microBlue) ReadCommand() (remote.Command, bool) {
 	command := remote.Command{}
 	state := byte(0)
 	id := make([]byte, 0, 5)
@@ -82,6 +84,28 @@ loop:
 		} else {
 			command.Op = remote.OpWave
 		}
+	case "m0", "m1", "m2", "m3", "m4", "m5", "m6", "m7", "m8", "m9":
+		if command.Args[0] == 0 {
+			// button released, the melody keeps playing
+			return command, false
+		}
+		command.Op = remote.OpMelody
+		command.Args[0] = int(id[1] - '0')
+	case "mc":
+		// beeps the value sent by the remote, for example from a slider
+		command.Op = remote.OpMorse
+	case "t0", "t1", "t2", "t3", "t4", "t5", "t6", "t7", "t8", "t9",
+		"v0", "v1", "v2", "v3", "v4", "v5", "v6", "v7", "v8", "v9":
+		if command.Args[0] == 0 {
+			return command, false
+		}
+		// t buttons send the message in Morse code, v buttons speak it
+		command.Op = remote.OpMessage
+		command.Args[0] = int(id[1] - '0')
+		command.Args[1] = 0
+		if id[0] == 'v' {
+			command.Args[1] = 1
+		}
 	case "c0", "c1", "c2", "c3", "c4", "c5", "c6", "c7", "c8", "c9":
 		if command.Args[0] == 0 {
 			command.Op = remote.OpHome
@@ -90,7 +114,7 @@ loop:
 			command.Args[0] = int(id[1] - '0')
 		}
 	}
-	return command
+	return command, true
 }
 
 func (remote *microBlue) Start() error {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"math/rand/v2"
 	"time"
 
@@ -9,37 +10,57 @@ import (
 )
 
 func obstacleAvoidanceWalkFn(us hcsr04.Device) ninja.CustomCommand {
-	return func(n *ninja.Ninja) error {
+	return func(ctx context.Context, n *ninja.Ninja) error {
 		start := time.Now()
-		n.Mode(ninja.ModeWalk)
+		if err := n.ModeContext(ctx, ninja.ModeWalk); err != nil {
+			return err
+		}
 		for time.Since(start) < time.Minute {
 			dist := us.ReadDistance()
 			if dist != 0 && dist < 150 {
-				n.Walk(-1)
-				n.RightLegSpin(20, 100*time.Duration(rand.IntN(9)+6)*time.Millisecond)
+				if err := n.WalkContext(ctx, -1); err != nil {
+					return err
+				}
+				if err := n.RightLegSpinContext(ctx, 20, 100*time.Duration(rand.IntN(9)+6)*time.Millisecond); err != nil {
+					return err
+				}
 				continue
 			}
 
-			n.Walk(1)
+			if err := n.WalkContext(ctx, 1); err != nil {
+				return err
+			}
 		}
 		return nil
 	}
 }
 
 func obstacleAvoidanceRollFn(us hcsr04.Device) ninja.CustomCommand {
-	return func(n *ninja.Ninja) error {
+	return func(ctx context.Context, n *ninja.Ninja) error {
 		start := time.Now()
-		n.Mode(ninja.ModeRoll)
+		if err := n.ModeContext(ctx, ninja.ModeRoll); err != nil {
+			return err
+		}
 		n.Roll(50, 0)
 		for time.Since(start) < time.Minute {
+			if err := ctx.Err(); err != nil {
+				n.RollStop()
+				return err
+			}
 			dist := us.ReadDistance()
 			if dist != 0 && dist < 150 {
 				n.RollStop()
-				time.Sleep(500 * time.Millisecond)
+				if err := n.PauseContext(ctx, 500*time.Millisecond); err != nil {
+					return err
+				}
 				n.Roll(-50, 0)
-				time.Sleep(500 * time.Millisecond)
+				if err := n.PauseContext(ctx, 500*time.Millisecond); err != nil {
+					return err
+				}
 				n.Roll(0, 50)
-				time.Sleep(100 * time.Duration(rand.IntN(9)+1) * time.Millisecond)
+				if err := n.PauseContext(ctx, 100*time.Duration(rand.IntN(9)+1)*time.Millisecond); err != nil {
+					return err
+				}
 				n.Roll(50, 0)
 			}
 		}
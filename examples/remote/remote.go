@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"machine"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/HattoriHanzo031/gotto/ninja"
 	"github.com/HattoriHanzo031/gotto/remote"
 	"github.com/HattoriHanzo031/gotto/servo"
+	"github.com/HattoriHanzo031/gotto/settings"
 
 	"tinygo.org/x/drivers/hcsr04"
 	tgservo "tinygo.org/x/drivers/servo"
@@ -42,23 +44,19 @@ func main() {
 	lfServo := servo.New360(must(footArr.Add(lFoot)), 450, 2550)
 	rfServo := servo.New360(must(footArr.Add(rFoot)), 450, 2550)
 
-	bz := buzzer.New(buzzer.NewPwmChannel(buzzerPwm, buzzerPin))
+	bz := buzzer.New(buzzer.NewPwmChannel(buzzerPwm, buzzerPin), nil)
 	err := bz.Configure()
 	if err != nil {
 		panic(err)
 	}
 
-	n := ninja.New(rlServo, llServo, rfServo, lfServo, bz)
+	n := ninja.New(rlServo, llServo, rfServo, lfServo, bz, nil)
 
-	n.Trim(ninja.Trim{
-		TiltAngle:         0,
-		LeftStepDuration:  0,
-		RightStepDuration: 150 * time.Millisecond,
-		LfSpeed:           0,
-		RfSpeed:           0,
-		LlAngle:           20,
-		RlAngle:           12,
-	})
+	trim, err := settings.NewFlashStore(machine.Flash, 0).LoadTrim()
+	if err != nil {
+		println("Using default trim:", err.Error())
+	}
+	n.Trim(trim)
 
 	// Initialize ultrasonic sensor
 	us := hcsr04.New(usTrig, usEcho)
@@ -68,6 +66,10 @@ func main() {
 	_ = n.SetCustomCommand(0, obstacleAvoidanceWalkFn(us))
 	_ = n.SetCustomCommand(1, obstacleAvoidanceRollFn(us))
 
+	// Messages spelled out with the t0 and t1 buttons of the remote, or spoken with v0 and v1
+	remote.RegisterMessage("hello")
+	remote.RegisterMessage("sos")
+
 	time.Sleep(500 * time.Millisecond)
 
 	// Play a tone to indicate the robot is ready
@@ -82,7 +84,12 @@ func main() {
 		return
 	}
 
-	commandCh := make(chan remote.Command, 1)
+	engine := ninja.NewEngine(n)
+	engine.SetErrorHandler(func(err error) {
+		println("Error executing command:", err.Error())
+	})
+
+	buttonCh := make(chan remote.Command, 1)
 
 	buttonCommand := remote.Command{
 		Op:   remote.OpSetMode,
@@ -97,8 +104,9 @@ func main() {
 		}
 		t = time.Now()
 
+		// Non-blocking send, since the engine can not be used from the interrupt handler
 		select {
-		case commandCh <- buttonCommand:
+		case buttonCh <- buttonCommand:
 			if buttonPin.Get() {
 				buttonCommand.Args[0] = 1
 			} else {
@@ -108,21 +116,25 @@ func main() {
 		}
 	})
 
+	go func() {
+		for cmd := range buttonCh {
+			engine.Submit(cmd.Motion(), cmd.Policy())
+		}
+	}()
+
 	go func() {
 		for {
-			// Non-blocking send to skip the command if previous command is not yet processed
-			select {
-			case commandCh <- rmt.ReadCommand():
-			default:
+			// Roll commands replace the current motion, so the latest joystick input wins,
+			// while other commands are queued behind the current motion
+			cmd, ok := rmt.ReadCommand()
+			if !ok {
+				continue
 			}
+			engine.Submit(cmd.Motion(), cmd.Policy())
 		}
 	}()
 
-	for cmd := range commandCh {
-		if err := cmd.Execute(n); err != nil {
-			println("Error executing command:", err.Error())
-		}
-	}
+	engine.Run(context.Background())
 }
 
 func must[T any](v T, err error) T {
@@ -8,6 +8,7 @@ import (
 	"github.com/HattoriHanzo031/gotto/buzzer"
 	"github.com/HattoriHanzo031/gotto/ninja"
 	"github.com/HattoriHanzo031/gotto/servo"
+	"github.com/HattoriHanzo031/gotto/settings"
 	tgservo "tinygo.org/x/drivers/servo"
 )
 
@@ -36,22 +37,18 @@ func main() {
 	lfServo := servo.New360(must(footArr.Add(lFoot)), 450, 2550)
 	rfServo := servo.New360(must(footArr.Add(rFoot)), 450, 2550)
 
-	bz := buzzer.New(buzzer.NewPwmChannel(pwmBuzzer, buzzerPin))
+	bz := buzzer.New(buzzer.NewPwmChannel(pwmBuzzer, buzzerPin), nil)
 	err := bz.Configure()
 	if err != nil {
 		panic(err)
 	}
 
-	n := ninja.New(rlServo, llServo, rfServo, lfServo, bz)
+	n := ninja.New(rlServo, llServo, rfServo, lfServo, bz, nil)
 
-	trim := ninja.Trim{
-		TiltAngle:         0,
-		LeftStepDuration:  0,
-		RightStepDuration: 150 * time.Millisecond,
-		LfSpeed:           0,
-		RfSpeed:           0,
-		LlAngle:           20,
-		RlAngle:           12,
+	store := settings.NewFlashStore(machine.Flash, 0)
+	trim, err := store.LoadTrim()
+	if err != nil {
+		println("Using default trim:", err.Error())
 	}
 
 	n.Trim(trim)
@@ -128,6 +125,12 @@ func main() {
 			println("tilt angle trim:", trim.TiltAngle)
 			n.Trim(trim)
 			testTilting(n)
+		case "save":
+			if err := store.SaveTrim(trim); err != nil {
+				println("Error saving trim:", err.Error())
+				continue
+			}
+			println("trim saved")
 		case "reset":
 			trim = ninja.Trim{}
 			n.Trim(trim)
@@ -0,0 +1,62 @@
+package ninja
+
+import "time"
+
+// Config holds the parameters of the robot's build and gait, so variants with different
+// servos, leg lengths or printed parts can be tuned without changing the package.
+// Zero WalkSpeed, TiltAngle, StepDuration, InitialLegAngle and Profile fields are replaced with
+// the DefaultConfig values, so only the fields that differ need to be set. The other fields
+// are used as is, since zero is a valid value for them.
+type Config struct {
+	// WalkSpeed is the speed of the feet when walking (0-100).
+	WalkSpeed int
+	// TiltAngle is the angle by which the legs tilt the robot to one side to lift a foot.
+	// The trim tilt angle is added to it.
+	TiltAngle int
+	// TiltOvershoot is added to the angle of the leg the robot tilts towards,
+	// so the robot leans over far enough to lift the other foot.
+	TiltOvershoot int
+	// StepDuration is the duration of each step when walking. The trim step durations are added to it.
+	StepDuration time.Duration
+	// InitialLegAngle is the angle both legs are assumed to be at when the Ninja is created,
+	// from which the first smooth move starts.
+	InitialLegAngle int
+	// RollHomePause is the pause between raising the left and the right leg when moving
+	// to the home position in roll mode, so the robot does not fall over.
+	RollHomePause time.Duration
+	// Profile is the move profile used for the leg moves, as set with SetProfile.
+	Profile Profile
+	// LeftLimits and RightLimits are the joint limits of the legs, as set with SetJointLimits.
+	LeftLimits, RightLimits JointLimits
+}
+
+// DefaultConfig is the configuration of the standard Ninja build, used by New.
+var DefaultConfig = Config{
+	WalkSpeed:       20,
+	TiltAngle:       45,
+	TiltOvershoot:   15,
+	StepDuration:    600 * time.Millisecond,
+	InitialLegAngle: 95,
+	RollHomePause:   200 * time.Millisecond,
+	Profile:         DefaultProfile,
+}
+
+// withDefaults returns the configuration with its zero fields replaced with the DefaultConfig values.
+func (c Config) withDefaults() Config {
+	if c.WalkSpeed == 0 {
+		c.WalkSpeed = DefaultConfig.WalkSpeed
+	}
+	if c.TiltAngle == 0 {
+		c.TiltAngle = DefaultConfig.TiltAngle
+	}
+	if c.StepDuration == 0 {
+		c.StepDuration = DefaultConfig.StepDuration
+	}
+	if c.InitialLegAngle == 0 {
+		c.InitialLegAngle = DefaultConfig.InitialLegAngle
+	}
+	if c.Profile == (Profile{}) {
+		c.Profile = DefaultConfig.Profile
+	}
+	return c
+}
@@ -0,0 +1,50 @@
+package ninja_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/clock"
+	"github.com/HattoriHanzo031/gotto/ninja"
+	"github.com/HattoriHanzo031/gotto/sim"
+)
+
+func TestNewWithConfigDefaults(t *testing.T) {
+	custom := ninja.Config{
+		WalkSpeed:       30,
+		TiltAngle:       40,
+		TiltOvershoot:   10,
+		StepDuration:    time.Second,
+		InitialLegAngle: 90,
+		RollHomePause:   time.Millisecond,
+		Profile:         ninja.Profile{Easing: ninja.EaseInstant},
+	}
+	partial := ninja.DefaultConfig
+	partial.WalkSpeed = 30
+	partial.TiltOvershoot = 0
+	partial.RollHomePause = 0
+
+	tests := []struct {
+		name string
+		cfg  ninja.Config
+		want ninja.Config
+	}{
+		{"zero config", ninja.Config{}, ninja.Config{
+			WalkSpeed:       ninja.DefaultConfig.WalkSpeed,
+			TiltAngle:       ninja.DefaultConfig.TiltAngle,
+			StepDuration:    ninja.DefaultConfig.StepDuration,
+			InitialLegAngle: ninja.DefaultConfig.InitialLegAngle,
+			Profile:         ninja.DefaultConfig.Profile,
+		}},
+		{"partial config", ninja.Config{WalkSpeed: 30}, partial},
+		{"full config", custom, custom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := sim.NewRobot(clock.NewVirtual(time.Time{})).NinjaWithConfig(nil, tt.cfg)
+			if got := n.Config(); got != tt.want {
+				t.Errorf("Config() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package ninja
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Motion is a motion that can be submitted to an Engine.
+// It should use the context variants of the Ninja methods, so it stops promptly when ctx is cancelled.
+type Motion func(ctx context.Context, n *Ninja) error
+
+// Policy determines how a motion submitted to an Engine is scheduled.
+type Policy int
+
+const (
+	// PolicyQueue runs the motion after the current motion and all previously queued motions.
+	PolicyQueue Policy = iota
+	// PolicyReplace cancels the current motion and discards all queued motions,
+	// so only the submitted motion runs. Useful for latest-wins input such as a joystick.
+	PolicyReplace
+	// PolicyPreempt cancels the current motion and runs the submitted motion
+	// before the queued motions.
+	PolicyPreempt
+)
+
+var (
+	ErrMotionDiscarded = errors.New("ninja: motion discarded")
+	ErrEngineStopped   = errors.New("ninja: engine stopped")
+)
+
+// Job represents a motion submitted to an Engine.
+type Job struct {
+	engine *Engine
+	motion Motion
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Done returns a channel that is closed when the job is completed, cancelled or discarded.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Wait waits for the job to complete and returns the error returned by the motion.
+// If the job was cancelled it returns context.Canceled, and if it was discarded
+// by another motion it returns ErrMotionDiscarded.
+func (j *Job) Wait() error {
+	<-j.done
+	return j.err
+}
+
+// Cancel cancels the job. If the job is running, its motion is cancelled,
+// otherwise it is removed from the queue.
+func (j *Job) Cancel() {
+	e := j.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if j == e.current {
+		j.cancel()
+		return
+	}
+	if i := slices.Index(e.queue, j); i >= 0 {
+		e.queue = slices.Delete(e.queue, i, i+1)
+		j.finish(context.Canceled)
+	}
+}
+
+func (j *Job) finish(err error) {
+	j.err = err
+	close(j.done)
+}
+
+// Engine runs motions on a Ninja in a dedicated goroutine that owns the servos.
+// Once the engine is running, the Ninja should only be used through the engine.
+type Engine struct {
+	n       *Ninja
+	mu      sync.Mutex
+	queue   []*Job
+	current *Job
+	stopped bool
+	wake    chan struct{}
+	onError func(error)
+}
+
+// NewEngine creates a new Engine running motions on the given Ninja.
+// The engine does not run motions until Run is called.
+func NewEngine(n *Ninja) *Engine {
+	return &Engine{
+		n:    n,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// SetErrorHandler sets a function that is called from the engine goroutine
+// with the errors returned by motions. Errors of cancelled motions are not reported.
+func (e *Engine) SetErrorHandler(fn func(error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onError = fn
+}
+
+// Submit submits the motion to the engine according to the given policy and returns
+// immediately. The returned Job can be used to wait for the motion to complete.
+// If the engine is stopped, the job completes immediately with ErrEngineStopped.
+func (e *Engine) Submit(m Motion, policy Policy) *Job {
+	job := &Job{
+		engine: e,
+		motion: m,
+		done:   make(chan struct{}),
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped {
+		job.finish(ErrEngineStopped)
+		return job
+	}
+
+	switch policy {
+	case PolicyReplace:
+		for _, j := range e.queue {
+			j.finish(ErrMotionDiscarded)
+		}
+		e.queue = append(e.queue[:0], job)
+		e.cancelCurrent()
+	case PolicyPreempt:
+		e.queue = slices.Insert(e.queue, 0, job)
+		e.cancelCurrent()
+	default:
+		e.queue = append(e.queue, job)
+	}
+
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+	return job
+}
+
+// Do submits the motion and waits for it to complete.
+func (e *Engine) Do(m Motion, policy Policy) error {
+	return e.Submit(m, policy).Wait()
+}
+
+func (e *Engine) cancelCurrent() {
+	if e.current != nil {
+		e.current.cancel()
+	}
+}
+
+// next removes the next job from the queue and makes it the current job.
+func (e *Engine) next(ctx context.Context) (*Job, context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.queue) == 0 {
+		return nil, nil
+	}
+	job := e.queue[0]
+	e.queue = slices.Delete(e.queue, 0, 1)
+	jobCtx, cancel := context.WithCancel(ctx)
+	job.cancel = cancel
+	e.current = job
+	return job, jobCtx
+}
+
+// Run runs the submitted motions one at a time until ctx is cancelled.
+// It should be called once, from the goroutine that owns the Ninja.
+// When ctx is cancelled, the current motion is cancelled, all queued motions are
+// discarded with ErrEngineStopped and Run returns the context error.
+func (e *Engine) Run(ctx context.Context) error {
+	for {
+		if ctx.Err() != nil {
+			e.stop()
+			return ctx.Err()
+		}
+
+		job, jobCtx := e.next(ctx)
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				e.stop()
+				return ctx.Err()
+			case <-e.wake:
+				continue
+			}
+		}
+
+		err := job.motion(jobCtx, e.n)
+		job.cancel()
+
+		e.mu.Lock()
+		e.current = nil
+		onError := e.onError
+		e.mu.Unlock()
+
+		job.finish(err)
+		if err != nil && !errors.Is(err, context.Canceled) && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+func (e *Engine) stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopped = true
+	for _, j := range e.queue {
+		j.finish(ErrEngineStopped)
+	}
+	e.queue = nil
+}
@@ -0,0 +1,219 @@
+package ninja_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/clock"
+	"github.com/HattoriHanzo031/gotto/ninja"
+	"github.com/HattoriHanzo031/gotto/sim"
+)
+
+// recorder records the names of the motions in the order they ran.
+type recorder struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (r *recorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ran = append(r.ran, name)
+}
+
+func (r *recorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.ran)
+}
+
+// motion returns a motion that records its name and returns immediately.
+func (r *recorder) motion(name string) ninja.Motion {
+	return func(ctx context.Context, n *ninja.Ninja) error {
+		r.record(name)
+		return nil
+	}
+}
+
+// blocking returns a motion that records its name, signals started and runs until it is cancelled.
+func (r *recorder) blocking(name string, started chan<- struct{}) ninja.Motion {
+	return func(ctx context.Context, n *ninja.Ninja) error {
+		r.record(name)
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+}
+
+// startEngine runs an engine for a simulated robot in virtual time until the test ends.
+func startEngine(t *testing.T) (*ninja.Engine, context.CancelFunc, <-chan error) {
+	t.Helper()
+	n := sim.NewRobot(clock.NewVirtual(time.Time{})).Ninja(nil)
+	e := ninja.NewEngine(n)
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	finished := make(chan struct{})
+	go func() {
+		errc <- e.Run(ctx)
+		close(finished)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-finished
+	})
+	return e, cancel, errc
+}
+
+func TestEnginePolicies(t *testing.T) {
+	tests := []struct {
+		name string
+		// submit submits the jobs while the blocking motion "a" is running.
+		submit func(e *ninja.Engine, r *recorder, a *ninja.Job) []*ninja.Job
+		// want is the order in which the motions ran.
+		want []string
+		// errs are the errors of "a" followed by the submitted jobs.
+		errs []error
+	}{
+		{
+			name: "queue and cancel running",
+			submit: func(e *ninja.Engine, r *recorder, a *ninja.Job) []*ninja.Job {
+				b := e.Submit(r.motion("b"), ninja.PolicyQueue)
+				c := e.Submit(r.motion("c"), ninja.PolicyQueue)
+				a.Cancel()
+				return []*ninja.Job{b, c}
+			},
+			want: []string{"a", "b", "c"},
+			errs: []error{context.Canceled, nil, nil},
+		},
+		{
+			name: "replace",
+			submit: func(e *ninja.Engine, r *recorder, a *ninja.Job) []*ninja.Job {
+				b := e.Submit(r.motion("b"), ninja.PolicyQueue)
+				c := e.Submit(r.motion("c"), ninja.PolicyReplace)
+				return []*ninja.Job{b, c}
+			},
+			want: []string{"a", "c"},
+			errs: []error{context.Canceled, ninja.ErrMotionDiscarded, nil},
+		},
+		{
+			name: "preempt",
+			submit: func(e *ninja.Engine, r *recorder, a *ninja.Job) []*ninja.Job {
+				b := e.Submit(r.motion("b"), ninja.PolicyQueue)
+				c := e.Submit(r.motion("c"), ninja.PolicyPreempt)
+				return []*ninja.Job{b, c}
+			},
+			want: []string{"a", "c", "b"},
+			errs: []error{context.Canceled, nil, nil},
+		},
+		{
+			name: "cancel queued",
+			submit: func(e *ninja.Engine, r *recorder, a *ninja.Job) []*ninja.Job {
+				b := e.Submit(r.motion("b"), ninja.PolicyQueue)
+				c := e.Submit(r.motion("c"), ninja.PolicyQueue)
+				b.Cancel()
+				a.Cancel()
+				return []*ninja.Job{b, c}
+			},
+			want: []string{"a", "c"},
+			errs: []error{context.Canceled, context.Canceled, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _, _ := startEngine(t)
+			r := &recorder{}
+			started := make(chan struct{})
+			a := e.Submit(r.blocking("a", started), ninja.PolicyQueue)
+			<-started
+
+			jobs := append([]*ninja.Job{a}, tt.submit(e, r, a)...)
+			for i, j := range jobs {
+				if err := j.Wait(); !errors.Is(err, tt.errs[i]) {
+					t.Errorf("job %d error = %v, want %v", i, err, tt.errs[i])
+				}
+			}
+			if got := r.names(); !slices.Equal(got, tt.want) {
+				t.Errorf("motions ran in order %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineStop(t *testing.T) {
+	e, stop, done := startEngine(t)
+	r := &recorder{}
+	started := make(chan struct{})
+	a := e.Submit(r.blocking("a", started), ninja.PolicyQueue)
+	<-started
+	b := e.Submit(r.motion("b"), ninja.PolicyQueue)
+
+	stop()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+	if err := a.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("running job error = %v, want %v", err, context.Canceled)
+	}
+	if err := b.Wait(); !errors.Is(err, ninja.ErrEngineStopped) {
+		t.Errorf("queued job error = %v, want %v", err, ninja.ErrEngineStopped)
+	}
+	if err := e.Do(r.motion("c"), ninja.PolicyQueue); !errors.Is(err, ninja.ErrEngineStopped) {
+		t.Errorf("Do() after stop error = %v, want %v", err, ninja.ErrEngineStopped)
+	}
+	if got, want := r.names(), []string{"a"}; !slices.Equal(got, want) {
+		t.Errorf("motions ran in order %v, want %v", got, want)
+	}
+}
+
+func TestEngineErrorHandler(t *testing.T) {
+	e, _, _ := startEngine(t)
+	errMotion := errors.New("motion failed")
+	var (
+		mu       sync.Mutex
+		reported []error
+	)
+	e.SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	})
+
+	fail := func(ctx context.Context, n *ninja.Ninja) error { return errMotion }
+	cancelled := func(ctx context.Context, n *ninja.Ninja) error { return context.Canceled }
+	if err := e.Do(fail, ninja.PolicyQueue); !errors.Is(err, errMotion) {
+		t.Errorf("Do() error = %v, want %v", err, errMotion)
+	}
+	if err := e.Do(cancelled, ninja.PolicyQueue); !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+	}
+	// the handler is called from the engine goroutine before it starts the next motion
+	if err := e.Do(func(ctx context.Context, n *ninja.Ninja) error { return nil }, ninja.PolicyQueue); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || !errors.Is(reported[0], errMotion) {
+		t.Errorf("reported errors = %v, want [%v]", reported, errMotion)
+	}
+}
+
+func TestEngineRunsNinjaMotions(t *testing.T) {
+	clk := clock.NewVirtual(time.Time{})
+	robot := sim.NewRobot(clk)
+	e := ninja.NewEngine(robot.Ninja(nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx)
+
+	walk := func(ctx context.Context, n *ninja.Ninja) error { return n.WalkContext(ctx, 2) }
+	if err := e.Do(walk, ninja.PolicyQueue); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if len(robot.LeftFoot.Timeline()) == 0 || len(robot.LeftLeg.Timeline()) == 0 {
+		t.Errorf("walk did not move the simulated joints")
+	}
+}
@@ -1,17 +1,17 @@
 package ninja
 
 import (
+	"context"
 	"errors"
+	"math"
 	"time"
 
 	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/clock"
 	"github.com/HattoriHanzo031/gotto/servo"
 )
 
 const (
-	walkSpeed         = 20
-	tiltAngle         = 45
-	stepDuration      = 600 * time.Millisecond
 	numCustomCommands = 10
 )
 
@@ -34,7 +34,9 @@ const (
 )
 
 // CustomCommand represents a user-defined command that can be executed on the Ninja robot.
-type CustomCommand func(*Ninja) error
+// Long-running commands should use the context variants of the Ninja methods and return
+// when ctx is cancelled, so they can be stopped by other commands.
+type CustomCommand func(ctx context.Context, n *Ninja) error
 
 var (
 	ErrInvalidMode                  = errors.New("ninja: invalid mode")
@@ -44,7 +46,7 @@ var (
 )
 
 // Trim represents the trim values for the robot's movement and posture adjustments.
-// TODO: trim should be persisted in non-volatile memory and applied on startup.
+// Trim can be persisted in non-volatile memory and applied on startup using the settings package.
 type Trim struct {
 	// TiltAngle is added to the tilt angle when tilting.
 	// Positive values increase the tilt angle, while negative values decrease it.
@@ -80,67 +82,174 @@ type Ninja struct {
 	err            error
 	trim           Trim
 	buzzer         *buzzer.Buzzer
+	player         *buzzer.Player
+	clock          clock.Clock
+	config         Config
 	customCommands [numCustomCommands]CustomCommand
 }
 
 // New creates a new Ninja instance with the given leg and foot servos and an optional buzzer.
 // The leg servos should be of type Servo180, while the foot servos should be of type Servo360.
 // The buzzer can be nil if not used, but it is required for using the BuzzerTone method.
+// The clock is used for all motion timing. If it is nil, the system clock is used.
 // The servos should be configured and ready to use before creating the Ninja instance.
-func New(rLeg, lLeg servo.Servo180, rFoot, lFoot servo.Servo360, buzzer *buzzer.Buzzer) *Ninja {
+// The Ninja uses DefaultConfig, use NewWithConfig for other builds.
+func New(rLeg, lLeg servo.Servo180, rFoot, lFoot servo.Servo360, buzzer *buzzer.Buzzer, clk clock.Clock) *Ninja {
+	return NewWithConfig(rLeg, lLeg, rFoot, lFoot, buzzer, clk, DefaultConfig)
+}
+
+// NewWithConfig is like New but uses the given configuration instead of DefaultConfig.
+// Zero fields of the configuration are replaced with the DefaultConfig values as described in Config.
+func NewWithConfig(rLeg, lLeg servo.Servo180, rFoot, lFoot servo.Servo360, bz *buzzer.Buzzer, clk clock.Clock, cfg Config) *Ninja {
+	if clk == nil {
+		clk = clock.System
+	}
+	cfg = cfg.withDefaults()
+	var player *buzzer.Player
+	if bz != nil {
+		player = buzzer.NewPlayer(bz)
+	}
 	return &Ninja{
 		rLeg:    rLeg,
 		rFoot:   rFoot,
 		lLeg:    lLeg,
 		lFoot:   lFoot,
-		llAngle: 95,
-		rlAngle: 95,
+		llAngle: cfg.InitialLegAngle,
+		rlAngle: cfg.InitialLegAngle,
 		trim:    Trim{},
 		mode:    ModeWalk,
-		buzzer:  buzzer,
+		buzzer:  bz,
+		player:  player,
+		clock:   clk,
+		config:  cfg,
 	}
 }
 
-// setAngleSmooth gradually changes the angle from current to new in 30 steps
-// TODO: make step count and delay configurable
-func setAngleSmooth(new, current int, set func(int) error) error {
-	increment := float32(new-current) / 30.0
-	for i := range 30 {
-		if err := set(current + int(increment*float32(i+1))); err != nil {
+// Config returns the configuration of the Ninja, including the changes made with SetProfile and SetJointLimits.
+func (n *Ninja) Config() Config {
+	return n.config
+}
+
+// jointMove represents a move of a leg joint from its current angle to the target angle.
+type jointMove struct {
+	target  int
+	current *int
+	limits  JointLimits
+	set     func(int) error
+}
+
+// setAnglesSmooth gradually moves all joints from their current angles to their targets
+// over the same time window, so they all arrive together. Joints without limits follow the
+// given profile, while joints with limits follow their trajectory stretched to the window,
+// which is long enough for the slowest joint.
+// current angles are updated after each step, so they hold the last angles set even if the move is cancelled.
+func (n *Ninja) setAnglesSmooth(ctx context.Context, profile Profile, moves ...jointMove) error {
+	steps, delay := profile.steps()
+	starts := make([]int, len(moves))
+	progress := make([]func(float64) float64, len(moves))
+	limited := false
+	for i, m := range moves {
+		starts[i] = *m.current
+		progress[i] = profile.Easing.at
+		limited = limited || m.limits.limited()
+	}
+
+	if limited {
+		if delay <= 0 {
+			delay = DefaultProfile.StepDuration
+		}
+		var window float64
+		for i, m := range moves {
+			if !m.limits.limited() {
+				if m.target != starts[i] {
+					window = max(window, float64(steps)*delay.Seconds())
+				}
+				continue
+			}
+			tr := m.limits.trajectory(math.Abs(float64(m.target - starts[i])))
+			window = max(window, tr.duration)
+			progress[i] = tr.at
+		}
+		steps = max(int(math.Ceil(window/delay.Seconds())), 1)
+	}
+
+	for i := range steps {
+		f := float64(i+1) / float64(steps)
+		for j, m := range moves {
+			angle := starts[j] + int(float64(m.target-starts[j])*progress[j](f))
+			if err := m.set(angle); err != nil {
+				return err
+			}
+			*m.current = angle
+		}
+		if err := n.wait(ctx, delay); err != nil {
 			return err
 		}
-		time.Sleep(5 * time.Millisecond)
+	}
+	for _, m := range moves {
+		*m.current = m.target
 	}
 	return nil
 }
 
-func (n *Ninja) lLegAngle(angle int) {
+// wait pauses for the given duration or until the context is cancelled.
+func (n *Ninja) wait(ctx context.Context, d time.Duration) error {
+	done := ctx.Done()
+	if done == nil {
+		n.clock.Sleep(d)
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-done:
+		return ctx.Err()
+	case <-n.clock.After(d):
+		return nil
+	}
+}
+
+func (n *Ninja) sleep(ctx context.Context, d time.Duration) {
 	if n.err != nil {
 		return
 	}
+	n.err = n.wait(ctx, d)
+}
 
+// lLegMove returns the move of the left leg to the given angle.
+func (n *Ninja) lLegMove(angle int) jointMove {
 	angle += n.trim.LlAngle
 	angle = 180 - angle
 
-	n.err = setAngleSmooth(angle, n.llAngle, n.lLeg.SetAngle)
-	if n.err != nil {
-		return
-	}
-	n.llAngle = angle
+	return jointMove{target: angle, current: &n.llAngle, limits: n.config.LeftLimits, set: n.lLeg.SetAngle}
 }
 
-func (n *Ninja) rLegAngle(angle int) {
-	if n.err != nil {
-		return
-	}
-
+// rLegMove returns the move of the right leg to the given angle.
+func (n *Ninja) rLegMove(angle int) jointMove {
 	angle += n.trim.RlAngle
 
-	n.err = setAngleSmooth(angle, n.rlAngle, n.rLeg.SetAngle)
+	return jointMove{target: angle, current: &n.rlAngle, limits: n.config.RightLimits, set: n.rLeg.SetAngle}
+}
+
+func (n *Ninja) moveLegs(ctx context.Context, moves ...jointMove) {
+	n.moveLegsProfile(ctx, n.config.Profile, moves...)
+}
+
+func (n *Ninja) moveLegsProfile(ctx context.Context, profile Profile, moves ...jointMove) {
 	if n.err != nil {
 		return
 	}
-	n.rlAngle = angle
+
+	n.err = n.setAnglesSmooth(ctx, profile, moves...)
+}
+
+func (n *Ninja) lLegAngle(ctx context.Context, angle int) {
+	n.moveLegs(ctx, n.lLegMove(angle))
+}
+
+func (n *Ninja) rLegAngle(ctx context.Context, angle int) {
+	n.moveLegs(ctx, n.rLegMove(angle))
 }
 
 func speedTrim(speed, trim int) int {
@@ -171,9 +280,20 @@ func (n *Ninja) lFootSpeed(speed int) {
 	n.err = n.lFoot.SetSpeed(speed)
 }
 
+// stop stops both feet regardless of the current error.
+func (n *Ninja) stop() {
+	_ = n.lFoot.SetSpeed(0)
+	_ = n.rFoot.SetSpeed(0)
+}
+
+// error returns and clears the current error.
+// If the error is caused by a cancelled context, the feet are stopped.
 func (n *Ninja) error() error {
 	err := n.err
 	n.err = nil
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		n.stop()
+	}
 	return err
 }
 
@@ -188,106 +308,224 @@ func (n *Ninja) Trim(trim Trim) {
 // dir can be TiltLeft, TiltRight, TiltReturnFromLeft, or TiltReturnFromRight.
 // It requires the robot to be in walk mode.
 func (n *Ninja) Tilt(dir TiltDir) error {
+	return n.TiltContext(context.Background(), dir)
+}
+
+// TiltContext is like Tilt but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) TiltContext(ctx context.Context, dir TiltDir) error {
+	return n.TiltProfileContext(ctx, dir, n.config.Profile)
+}
+
+// TiltProfile is like Tilt but moves the legs with the given profile
+// instead of the one set with SetProfile.
+func (n *Ninja) TiltProfile(dir TiltDir, profile Profile) error {
+	return n.TiltProfileContext(context.Background(), dir, profile)
+}
+
+// TiltProfileContext is like TiltProfile but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) TiltProfileContext(ctx context.Context, dir TiltDir, profile Profile) error {
 	if n.mode != ModeWalk {
 		return ErrInvalidMode
 	}
-	angle := tiltAngle + n.trim.TiltAngle
+	n.tiltProfile(ctx, dir, profile)
+	return n.error()
+}
+
+func (n *Ninja) tilt(ctx context.Context, dir TiltDir) {
+	n.tiltProfile(ctx, dir, n.config.Profile)
+}
+
+func (n *Ninja) tiltProfile(ctx context.Context, dir TiltDir, profile Profile) {
+	if n.err != nil {
+		return
+	}
+
+	angle := n.config.TiltAngle + n.trim.TiltAngle
+	overshoot := n.config.TiltOvershoot
 	switch dir {
-	case TiltReturnFromLeft:
-		n.lLegAngle(90)
-		n.rLegAngle(90)
-	case TiltReturnFromRight:
-		n.rLegAngle(90)
-		n.lLegAngle(90)
+	case TiltReturnFromLeft, TiltReturnFromRight:
+		n.moveLegsProfile(ctx, profile, n.lLegMove(90), n.rLegMove(90))
 	case TiltLeft:
-		n.rLegAngle(90 + angle + 15)
-		n.lLegAngle(90 - angle)
+		n.moveLegsProfile(ctx, profile, n.lLegMove(90-angle), n.rLegMove(90+angle+overshoot))
 	case TiltRight:
-		n.lLegAngle(90 + angle + 15)
-		n.rLegAngle(90 - angle)
+		n.moveLegsProfile(ctx, profile, n.lLegMove(90+angle+overshoot), n.rLegMove(90-angle))
 	default:
-		return ErrInvalidDirection
+		n.err = ErrInvalidDirection
 	}
-
-	return n.error()
 }
 
 // Mode sets the robot's mode to either walk or roll.
 // It also moves the robot to its home position for the new mode.
 func (n *Ninja) Mode(mode Mode) error {
+	return n.ModeContext(context.Background(), mode)
+}
+
+// ModeContext is like Mode but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) ModeContext(ctx context.Context, mode Mode) error {
 	if n.mode != ModeWalk && mode != ModeRoll {
 		return ErrInvalidMode
 	}
 
 	n.mode = mode
-	return n.Home()
+	return n.HomeContext(ctx)
 }
 
 // Home moves the robot to its home position.
 // Home position in walk mode is standing straight with feet together.
 // Home position in roll mode both legs raised to the side.
 func (n *Ninja) Home() error {
+	return n.HomeContext(context.Background())
+}
+
+// HomeContext is like Home but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) HomeContext(ctx context.Context) error {
 	n.lFootSpeed(0)
 	n.rFootSpeed(0)
 	switch n.mode {
 	case ModeWalk:
-		n.lLegAngle(90)
-		n.rLegAngle(90)
+		n.moveLegs(ctx, n.lLegMove(90), n.rLegMove(90))
 	case ModeRoll:
-		n.lLegAngle(0)
-		time.Sleep(200 * time.Millisecond)
-		n.rLegAngle(0)
+		n.lLegAngle(ctx, 0)
+		n.sleep(ctx, n.config.RollHomePause)
+		n.rLegAngle(ctx, 0)
 	}
 	return n.error()
 }
 
+// MoveLegs moves both legs to the given angles at the same time, so they arrive together.
+// The angles are in degrees, where 90 is the home position in walk mode and 0 is the
+// home position in roll mode. Leg angle trims are applied.
+func (n *Ninja) MoveLegs(left, right int) error {
+	return n.MoveLegsContext(context.Background(), left, right)
+}
+
+// MoveLegsContext is like MoveLegs but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) MoveLegsContext(ctx context.Context, left, right int) error {
+	n.moveLegs(ctx, n.lLegMove(left), n.rLegMove(right))
+	return n.error()
+}
+
+// MoveLegsProfile is like MoveLegs but moves the legs with the given profile
+// instead of the one set with SetProfile.
+func (n *Ninja) MoveLegsProfile(left, right int, profile Profile) error {
+	return n.MoveLegsProfileContext(context.Background(), left, right, profile)
+}
+
+// MoveLegsProfileContext is like MoveLegsProfile but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) MoveLegsProfileContext(ctx context.Context, left, right int, profile Profile) error {
+	n.moveLegsProfile(ctx, profile, n.lLegMove(left), n.rLegMove(right))
+	return n.error()
+}
+
+// SetFeetSpeed sets the speed of both feet in any mode. Speed trims are applied.
+// Positive speeds spin the feet in the direction that moves the robot forward in roll mode.
+// The feet keep spinning until their speed is set to zero.
+func (n *Ninja) SetFeetSpeed(left, right int) error {
+	n.lFootSpeed(left)
+	n.rFootSpeed(right)
+	return n.error()
+}
+
+// Pause pauses for the given duration using the robot's clock, keeping the robot as it is.
+func (n *Ninja) Pause(d time.Duration) error {
+	return n.PauseContext(context.Background(), d)
+}
+
+// PauseContext is like Pause but stops the feet and returns the context error if ctx is cancelled.
+func (n *Ninja) PauseContext(ctx context.Context, d time.Duration) error {
+	n.sleep(ctx, d)
+	return n.error()
+}
+
 // MoveLeftFoot spins the left foot with the given speed and duration, then stops it.
 func (n *Ninja) MoveLeftFoot(speed int, duration time.Duration) error {
+	return n.MoveLeftFootContext(context.Background(), speed, duration)
+}
+
+// MoveLeftFootContext is like MoveLeftFoot but stops the foot and returns the context error if ctx is cancelled.
+func (n *Ninja) MoveLeftFootContext(ctx context.Context, speed int, duration time.Duration) error {
+	n.moveLeftFoot(ctx, speed, duration)
+	return n.error()
+}
+
+func (n *Ninja) moveLeftFoot(ctx context.Context, speed int, duration time.Duration) {
 	n.lFootSpeed(speed)
-	time.Sleep(duration)
+	n.sleep(ctx, duration)
 	n.lFootSpeed(0)
-	return n.error()
 }
 
 // MoveRightFoot spins the right foot with the given speed and duration, then stops it.
 func (n *Ninja) MoveRightFoot(speed int, duration time.Duration) error {
+	return n.MoveRightFootContext(context.Background(), speed, duration)
+}
+
+// MoveRightFootContext is like MoveRightFoot but stops the foot and returns the context error if ctx is cancelled.
+func (n *Ninja) MoveRightFootContext(ctx context.Context, speed int, duration time.Duration) error {
+	n.moveRightFoot(ctx, speed, duration)
+	return n.error()
+}
+
+func (n *Ninja) moveRightFoot(ctx context.Context, speed int, duration time.Duration) {
 	n.rFootSpeed(speed)
-	time.Sleep(duration)
+	n.sleep(ctx, duration)
 	n.rFootSpeed(0)
-	return n.error()
 }
 
 // LeftLegSpin performs a tilt and spinning motion on the left leg with the given speed and duration.
 // Positive speed spins clockwise, while negative speed spins counterclockwise.
 // It requires the robot to be in walk mode.
 func (n *Ninja) LeftLegSpin(speed int, duration time.Duration) error {
+	return n.LeftLegSpinContext(context.Background(), speed, duration)
+}
+
+// LeftLegSpinContext is like LeftLegSpin but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) LeftLegSpinContext(ctx context.Context, speed int, duration time.Duration) error {
 	if n.mode != ModeWalk {
 		return ErrInvalidMode
 	}
-	n.Tilt(TiltLeft)
-	n.MoveLeftFoot(speed, duration)
-	n.Tilt(TiltReturnFromLeft)
+	n.leftLegSpin(ctx, speed, duration)
 	return n.error()
 }
 
+func (n *Ninja) leftLegSpin(ctx context.Context, speed int, duration time.Duration) {
+	n.tilt(ctx, TiltLeft)
+	n.moveLeftFoot(ctx, speed, duration)
+	n.tilt(ctx, TiltReturnFromLeft)
+}
+
 // RightLegSpin performs a tilt and spinning motion on the right leg with the given speed and duration.
 // Positive speed spins clockwise, while negative speed spins counterclockwise.
 // It requires the robot to be in walk mode.
 func (n *Ninja) RightLegSpin(speed int, duration time.Duration) error {
+	return n.RightLegSpinContext(context.Background(), speed, duration)
+}
+
+// RightLegSpinContext is like RightLegSpin but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) RightLegSpinContext(ctx context.Context, speed int, duration time.Duration) error {
 	if n.mode != ModeWalk {
 		return ErrInvalidMode
 	}
-	n.Tilt(TiltRight)
-	n.MoveRightFoot(speed, duration)
-	n.Tilt(TiltReturnFromRight)
+	n.rightLegSpin(ctx, speed, duration)
 	return n.error()
 }
 
+func (n *Ninja) rightLegSpin(ctx context.Context, speed int, duration time.Duration) {
+	n.tilt(ctx, TiltRight)
+	n.moveRightFoot(ctx, speed, duration)
+	n.tilt(ctx, TiltReturnFromRight)
+}
+
 // Walk performs a walking motion for the given number of steps.
 // Positive steps walk forward, while negative steps walk backward.
 // Each step consists of stepping with both legs.
 // It requires the robot to be in walk mode.
 func (n *Ninja) Walk(steps int) error {
+	return n.WalkContext(context.Background(), steps)
+}
+
+// WalkContext is like Walk but stops the motion and returns the context error if ctx is cancelled,
+// without finishing the remaining steps.
+func (n *Ninja) WalkContext(ctx context.Context, steps int) error {
 	if n.mode != ModeWalk {
 		return ErrInvalidMode
 	}
@@ -296,18 +534,21 @@ func (n *Ninja) Walk(steps int) error {
 		return nil
 	}
 
-	speed := walkSpeed
+	speed := n.config.WalkSpeed
 	if steps < 0 {
-		speed = -walkSpeed
+		speed = -speed
 		steps = -steps
 	}
 
-	rStepDuration := stepDuration + n.trim.RightStepDuration
-	lStepDuration := stepDuration + n.trim.LeftStepDuration
+	rStepDuration := n.config.StepDuration + n.trim.RightStepDuration
+	lStepDuration := n.config.StepDuration + n.trim.LeftStepDuration
 
 	for range steps {
-		n.err = n.LeftLegSpin(speed, lStepDuration)
-		n.err = n.RightLegSpin(speed, rStepDuration)
+		n.leftLegSpin(ctx, speed, lStepDuration)
+		n.rightLegSpin(ctx, speed, rStepDuration)
+		if n.err != nil {
+			break
+		}
 	}
 
 	return n.error()
@@ -328,6 +569,12 @@ func (n *Ninja) SetCustomCommand(index int, fn CustomCommand) error {
 // If the index is out of range, it returns an ErrCustomCommandIndexOutOfRange error.
 // If there is no custom command set at the index, the command has no effect.
 func (n *Ninja) ExecuteCustomCommand(index int) error {
+	return n.ExecuteCustomCommandContext(context.Background(), index)
+}
+
+// ExecuteCustomCommandContext is like ExecuteCustomCommand but passes ctx to the custom command,
+// which should stop and return the context error if ctx is cancelled.
+func (n *Ninja) ExecuteCustomCommandContext(ctx context.Context, index int) error {
 	if index < 0 || index >= len(n.customCommands) {
 		return ErrCustomCommandIndexOutOfRange
 	}
@@ -335,7 +582,7 @@ func (n *Ninja) ExecuteCustomCommand(index int) error {
 		return nil
 	}
 
-	return n.customCommands[index](n)
+	return n.customCommands[index](ctx, n)
 }
 
 // Roll performs a rolling motion with the given throttle and turn values.
@@ -365,7 +612,7 @@ func (n *Ninja) StartLeftSpin(speed int) error {
 		return ErrInvalidMode
 	}
 
-	n.err = n.Tilt(TiltLeft)
+	n.tilt(context.Background(), TiltLeft)
 	n.lFootSpeed(speed)
 	return n.error()
 }
@@ -376,7 +623,7 @@ func (n *Ninja) StopLeftSpin() error {
 		return ErrInvalidMode
 	}
 	n.lFootSpeed(0)
-	n.err = n.Tilt(TiltReturnFromLeft)
+	n.tilt(context.Background(), TiltReturnFromLeft)
 	return n.error()
 }
 
@@ -388,7 +635,7 @@ func (n *Ninja) StartRightSpin(speed int) error {
 		return ErrInvalidMode
 	}
 
-	n.err = n.Tilt(TiltRight)
+	n.tilt(context.Background(), TiltRight)
 	n.rFootSpeed(speed)
 	return n.error()
 }
@@ -399,34 +646,91 @@ func (n *Ninja) StopRightSpin() error {
 		return ErrInvalidMode
 	}
 	n.rFootSpeed(0)
-	n.err = n.Tilt(TiltReturnFromRight)
+	n.tilt(context.Background(), TiltReturnFromRight)
 	return n.error()
 }
 
 // Wave performs a waving motion with the left leg. It requires the robot to be in walk mode.
 func (n *Ninja) Wave() error {
+	return n.WaveContext(context.Background())
+}
+
+// WaveContext is like Wave but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) WaveContext(ctx context.Context) error {
+	return n.WaveProfileContext(ctx, n.config.Profile)
+}
+
+// WaveProfile is like Wave but moves the legs with the given profile
+// instead of the one set with SetProfile, for example to make the wave snappier.
+func (n *Ninja) WaveProfile(profile Profile) error {
+	return n.WaveProfileContext(context.Background(), profile)
+}
+
+// WaveProfileContext is like WaveProfile but stops the motion and returns the context error if ctx is cancelled.
+func (n *Ninja) WaveProfileContext(ctx context.Context, profile Profile) error {
 	if n.mode != ModeWalk {
 		return ErrInvalidMode
 	}
-	n.err = n.Tilt(TiltRight)
-	time.Sleep(500 * time.Millisecond)
+	n.tiltProfile(ctx, TiltRight, profile)
+	n.sleep(ctx, 500*time.Millisecond)
 
 	angle := n.llAngle
 	for range 4 {
-		n.lLegAngle(angle + 30)
-		n.lLegAngle(angle)
+		n.moveLegsProfile(ctx, profile, n.lLegMove(angle+30))
+		n.moveLegsProfile(ctx, profile, n.lLegMove(angle))
 	}
 
-	time.Sleep(500 * time.Millisecond)
-	n.err = n.Tilt(TiltReturnFromRight)
+	n.sleep(ctx, 500*time.Millisecond)
+	n.tiltProfile(ctx, TiltReturnFromRight, profile)
 	return n.error()
 }
 
 // BuzzerTone plays a tone on the buzzer
+// A melody started with StartMelody is stopped first, so the two do not mix.
 // If  buzzer is not configured, it returns an ErrBuzzerNotConfigured error
 func (n *Ninja) BuzzerTone(note buzzer.Note) error {
+	return n.BuzzerToneContext(context.Background(), note)
+}
+
+// BuzzerToneContext is like BuzzerTone but cuts the tone short and returns the context error if ctx is cancelled.
+func (n *Ninja) BuzzerToneContext(ctx context.Context, note buzzer.Note) error {
+	if n.buzzer == nil {
+		return ErrBuzzerNotConfigured
+	}
+	n.StopMelody()
+	return n.buzzer.ToneContext(ctx, note)
+}
+
+// BuzzerMelody plays a melody on the buzzer.
+// A melody started with StartMelody is stopped first, so the two do not mix.
+// If buzzer is not configured, it returns an ErrBuzzerNotConfigured error
+func (n *Ninja) BuzzerMelody(melody []buzzer.Note) error {
+	return n.BuzzerMelodyContext(context.Background(), melody)
+}
+
+// BuzzerMelodyContext is like BuzzerMelody but stops the melody and returns the context error if ctx is cancelled.
+func (n *Ninja) BuzzerMelodyContext(ctx context.Context, melody []buzzer.Note) error {
 	if n.buzzer == nil {
 		return ErrBuzzerNotConfigured
 	}
-	return n.buzzer.Tone(note)
+	n.StopMelody()
+	return n.buzzer.PlayMelodyContext(ctx, melody)
+}
+
+// StartMelody starts playing a melody on the buzzer in the background and returns immediately,
+// so the robot can keep moving while the music plays. A melody that is already playing is stopped.
+// The returned Playback can be used to pause, stop or wait for the melody.
+// If buzzer is not configured, it returns an ErrBuzzerNotConfigured error
+func (n *Ninja) StartMelody(melody []buzzer.Note) (*buzzer.Playback, error) {
+	if n.player == nil {
+		return nil, ErrBuzzerNotConfigured
+	}
+	return n.player.Play(melody), nil
+}
+
+// StopMelody stops the melody started with StartMelody, if it is still playing.
+func (n *Ninja) StopMelody() {
+	if n.player != nil {
+		n.player.Stop()
+	}
 }
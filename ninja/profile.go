@@ -0,0 +1,149 @@
+package ninja
+
+import (
+	"math"
+	"time"
+)
+
+// Easing determines how a smooth leg move progresses from the current angle to the new one.
+type Easing int
+
+const (
+	// EaseLinear moves at a constant speed.
+	EaseLinear Easing = iota
+	// EaseInOutCosine starts and ends slowly, following a half cosine wave.
+	EaseInOutCosine
+	// EaseCubic starts and ends slowly, accelerating and decelerating more sharply than EaseInOutCosine.
+	EaseCubic
+	// EaseInstant moves to the new angle in a single step.
+	EaseInstant
+)
+
+// at returns the progress of the move (0-1) at the given fraction of its duration (0-1).
+func (e Easing) at(t float64) float64 {
+	switch e {
+	case EaseInOutCosine:
+		return (1 - math.Cos(math.Pi*t)) / 2
+	case EaseCubic:
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		t = 2 - 2*t
+		return 1 - t*t*t/2
+	case EaseInstant:
+		return 1
+	default:
+		return t
+	}
+}
+
+// Profile describes how the legs move smoothly from their current angle to a new one.
+type Profile struct {
+	// Easing is the easing of the move.
+	Easing Easing
+	// Steps is the number of angles set during the move. EaseInstant always uses a single step.
+	Steps int
+	// StepDuration is the pause after each step.
+	StepDuration time.Duration
+	// Duration is the total duration of the move. If it is set, it takes precedence over StepDuration.
+	Duration time.Duration
+}
+
+// DefaultProfile is the profile used if no other profile is set: 30 linear steps 5ms apart.
+var DefaultProfile = Profile{
+	Easing:       EaseLinear,
+	Steps:        30,
+	StepDuration: 5 * time.Millisecond,
+}
+
+// steps returns the number of steps and the pause after each step.
+func (p Profile) steps() (int, time.Duration) {
+	steps := max(p.Steps, 1)
+	if p.Easing == EaseInstant {
+		steps = 1
+	}
+	if p.Duration > 0 {
+		return steps, p.Duration / time.Duration(steps)
+	}
+	return steps, p.StepDuration
+}
+
+// SetProfile sets the profile used for the leg moves of all motions.
+// Use MoveLegsProfile, TiltProfile or WaveProfile to use a different profile for a single call.
+func (n *Ninja) SetProfile(p Profile) {
+	n.config.Profile = p
+}
+
+// JointLimits limits the speed of a leg joint, so the duration of a move depends on its distance.
+// When the velocity or acceleration is limited, the joint follows a trapezoidal velocity trajectory
+// within the limits instead of the profile easing. The profile then only determines the pause
+// between the steps of the move.
+type JointLimits struct {
+	// MaxVelocity is the maximum angular velocity in degrees per second. Zero means unlimited.
+	MaxVelocity float64
+	// MaxAcceleration is the maximum angular acceleration in degrees per second squared.
+	// Zero means unlimited, in which case the joint moves at MaxVelocity from start to end.
+	MaxAcceleration float64
+}
+
+// limited returns true if any of the limits is set.
+func (l JointLimits) limited() bool {
+	return l.MaxVelocity > 0 || l.MaxAcceleration > 0
+}
+
+// trajectory describes a trapezoidal velocity trajectory: the joint accelerates to the peak velocity,
+// keeps it and then decelerates to stop at the target. If the distance is too short to reach the
+// maximum velocity, the trajectory is triangular.
+type trajectory struct {
+	distance  float64 // in degrees
+	duration  float64 // in seconds
+	accelTime float64 // in seconds, zero for constant velocity
+	accel     float64 // in degrees per second squared
+	velocity  float64 // peak velocity in degrees per second
+}
+
+// trajectory computes the fastest trajectory within the limits for the given distance in degrees.
+func (l JointLimits) trajectory(distance float64) trajectory {
+	tr := trajectory{distance: distance, accel: l.MaxAcceleration, velocity: l.MaxVelocity}
+	switch {
+	case distance == 0:
+	case l.MaxAcceleration <= 0:
+		tr.duration = distance / l.MaxVelocity
+	case l.MaxVelocity <= 0 || distance < l.MaxVelocity*l.MaxVelocity/l.MaxAcceleration:
+		tr.accelTime = math.Sqrt(distance / l.MaxAcceleration)
+		tr.velocity = l.MaxAcceleration * tr.accelTime
+		tr.duration = 2 * tr.accelTime
+	default:
+		tr.accelTime = l.MaxVelocity / l.MaxAcceleration
+		tr.duration = 2*tr.accelTime + (distance-l.MaxVelocity*tr.accelTime)/l.MaxVelocity
+	}
+	return tr
+}
+
+// at returns the progress of the move (0-1) at the given fraction of its duration (0-1).
+func (tr trajectory) at(f float64) float64 {
+	if tr.distance == 0 || f >= 1 {
+		return 1
+	}
+	t := f * tr.duration
+	var pos float64
+	switch {
+	case tr.accelTime == 0:
+		pos = tr.velocity * t
+	case t < tr.accelTime:
+		pos = tr.accel * t * t / 2
+	case t < tr.duration-tr.accelTime:
+		pos = tr.accel*tr.accelTime*tr.accelTime/2 + tr.velocity*(t-tr.accelTime)
+	default:
+		rem := tr.duration - t
+		pos = tr.distance - tr.accel*rem*rem/2
+	}
+	return pos / tr.distance
+}
+
+// SetJointLimits sets the velocity and acceleration limits of the left and right leg joints.
+// Zero limits, which are the default, make the legs move according to the profile only.
+func (n *Ninja) SetJointLimits(left, right JointLimits) {
+	n.config.LeftLimits = left
+	n.config.RightLimits = right
+}
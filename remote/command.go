@@ -2,7 +2,9 @@
 package remote
 
 import (
+	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/HattoriHanzo031/gotto/buzzer"
@@ -24,6 +26,15 @@ const (
 	OpBuzzerTone
 	OpWave
 	OpCustom
+	// OpMelody starts playing the melody registered in the buzzer package with the index in Args[0]
+	// in the background, so the following commands run while it plays.
+	OpMelody
+	// OpMorse beeps the number in Args[0] in Morse code, for example to read out a sensor value.
+	// Use OpMessage for text.
+	OpMorse
+	// OpMessage plays the message registered with RegisterMessage with the index in Args[0],
+	// in Morse code if Args[1] is 0 or as speech-like beeps if it is 1.
+	OpMessage
 )
 
 var (
@@ -40,29 +51,34 @@ type Command struct {
 
 // Execute performs the command on the given Ninja instance.
 func (c *Command) Execute(n *ninja.Ninja) error {
+	return c.ExecuteContext(context.Background(), n)
+}
+
+// ExecuteContext is like Execute but stops the motion and returns the context error if ctx is cancelled.
+func (c *Command) ExecuteContext(ctx context.Context, n *ninja.Ninja) error {
 	switch c.Op {
 	case OpSetMode:
 		switch c.Args[0] {
 		case 0:
-			return n.Mode(ninja.ModeWalk)
+			return n.ModeContext(ctx, ninja.ModeWalk)
 		case 1:
-			return n.Mode(ninja.ModeRoll)
+			return n.ModeContext(ctx, ninja.ModeRoll)
 		}
 	case OpHome:
-		return n.Home()
+		return n.HomeContext(ctx)
 	case OpTiltLeft:
 		switch c.Args[0] {
 		case 0:
-			return n.Tilt(ninja.TiltReturnFromLeft)
+			return n.TiltContext(ctx, ninja.TiltReturnFromLeft)
 		case 1:
-			return n.Tilt(ninja.TiltLeft)
+			return n.TiltContext(ctx, ninja.TiltLeft)
 		}
 	case OpTiltRight:
 		switch c.Args[0] {
 		case 0:
-			return n.Tilt(ninja.TiltReturnFromRight)
+			return n.TiltContext(ctx, ninja.TiltReturnFromRight)
 		case 1:
-			return n.Tilt(ninja.TiltRight)
+			return n.TiltContext(ctx, ninja.TiltRight)
 		}
 	case OpLeftLegSpin:
 		if c.Args[0] == 1 {
@@ -77,20 +93,60 @@ func (c *Command) Execute(n *ninja.Ninja) error {
 			return n.StopRightSpin()
 		}
 	case OpWalk:
-		return n.Walk(c.Args[0])
+		return n.WalkContext(ctx, c.Args[0])
 	case OpRoll:
 		return n.Roll(c.Args[1], c.Args[0])
 	case OpBuzzerTone:
-		return n.BuzzerTone(buzzer.Note{
+		return n.BuzzerToneContext(ctx, buzzer.Note{
 			Period:   buzzer.NotePeriod(c.Args[0]),
 			Duration: time.Duration(c.Args[1]) * time.Millisecond,
 		})
 	case OpWave:
-		return n.Wave()
+		return n.WaveContext(ctx)
 	case OpCustom:
-		return n.ExecuteCustomCommand(c.Args[0])
+		return n.ExecuteCustomCommandContext(ctx, c.Args[0])
+	case OpMelody:
+		melody, err := buzzer.MelodyAt(c.Args[0])
+		if err != nil {
+			return err
+		}
+		_, err = n.StartMelody(melody.Notes)
+		return err
+	case OpMorse:
+		return n.BuzzerMelodyContext(ctx, buzzer.DefaultMorse.Encode(strconv.Itoa(c.Args[0])))
+	case OpMessage:
+		text, err := MessageAt(c.Args[0])
+		if err != nil {
+			return err
+		}
+		switch c.Args[1] {
+		case 0:
+			return n.BuzzerMelodyContext(ctx, buzzer.DefaultMorse.Encode(text))
+		case 1:
+			return n.BuzzerMelodyContext(ctx, buzzer.DefaultVoice.Speak(text))
+		}
 	default:
 		return ErrUnknownCommand
 	}
 	return nil
 }
+
+// Motion returns the command as a motion that can be submitted to a ninja.Engine.
+func (c *Command) Motion() ninja.Motion {
+	cmd := *c
+	return func(ctx context.Context, n *ninja.Ninja) error {
+		return cmd.ExecuteContext(ctx, n)
+	}
+}
+
+// Policy returns the policy with which the command should be submitted to a ninja.Engine.
+// Rolling, mode and home commands replace whatever the robot is doing, so the latest
+// joystick input always wins. All other commands are queued.
+func (c *Command) Policy() ninja.Policy {
+	switch c.Op {
+	case OpRoll, OpSetMode, OpHome:
+		return ninja.PolicyReplace
+	default:
+		return ninja.PolicyQueue
+	}
+}
@@ -0,0 +1,34 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrMessageNotFound = errors.New("remote: message not found")
+
+var (
+	messagesMu sync.Mutex
+	messages   []string
+)
+
+// RegisterMessage adds the text to the messages that can be sent with OpMessage and returns its index.
+// Since commands only carry numbers, the robot and the remote agree on the messages by their index,
+// which is stable as long as messages are registered in the same order, for example from an init function.
+func RegisterMessage(text string) int {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	messages = append(messages, text)
+	return len(messages) - 1
+}
+
+// MessageAt returns the registered message with the given index.
+func MessageAt(index int) (string, error) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	if index < 0 || index >= len(messages) {
+		return "", fmt.Errorf("%w: index %d", ErrMessageNotFound, index)
+	}
+	return messages[index], nil
+}
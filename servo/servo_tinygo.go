@@ -1,3 +1,5 @@
+//go:build tinygo
+
 // TinyGo servo wrapper for ninja servos
 package servo
 
@@ -0,0 +1,45 @@
+package settings
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+// FileStore is a Store that keeps the trim in a file. It is meant for use on the host,
+// for example together with the sim package.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore creates a new FileStore that keeps the trim in the file at the given path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+// LoadTrim loads the trim from the file.
+// It returns ErrNotFound if the file does not exist.
+func (s *FileStore) LoadTrim() (ninja.Trim, error) {
+	record, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ninja.Trim{}, ErrNotFound
+	}
+	if err != nil {
+		return ninja.Trim{}, err
+	}
+	return decodeTrim(record)
+}
+
+// SaveTrim saves the trim to the file. The trim is first written to a temporary file
+// which then replaces the previous file, so a failed save does not corrupt it.
+func (s *FileStore) SaveTrim(trim ninja.Trim) error {
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, encodeTrim(trim), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,89 @@
+package settings
+
+import (
+	"encoding/binary"
+
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+// BlockDevice is an interface that abstracts a flash memory block device.
+// It is implemented by machine.Flash in TinyGo.
+type BlockDevice interface {
+	// ReadAt reads len(p) bytes from the device at the given offset.
+	ReadAt(p []byte, off int64) (n int, err error)
+	// WriteAt writes p to the device at the given offset. The memory must be erased first.
+	WriteAt(p []byte, off int64) (n int, err error)
+	// Size returns the size of the device in bytes.
+	Size() int64
+	// WriteBlockSize returns the size of the smallest block that can be written.
+	WriteBlockSize() int64
+	// EraseBlockSize returns the size of the smallest block that can be erased.
+	EraseBlockSize() int64
+	// EraseBlocks erases the given number of blocks starting at the given block.
+	EraseBlocks(start, len int64) error
+}
+
+// FlashStore is a Store that keeps the trim in a flash memory block device.
+type FlashStore struct {
+	dev    BlockDevice
+	offset int64
+}
+
+// NewFlashStore creates a new FlashStore that keeps the trim in the given device at the given offset.
+// The offset must be aligned to the erase block size of the device, since saving erases the
+// blocks holding the trim. On TinyGo, machine.Flash can be used as the device.
+func NewFlashStore(dev BlockDevice, offset int64) *FlashStore {
+	return &FlashStore{
+		dev:    dev,
+		offset: offset,
+	}
+}
+
+// LoadTrim loads the trim from the flash memory.
+// It returns ErrNotFound if no trim was saved yet.
+func (s *FlashStore) LoadTrim() (ninja.Trim, error) {
+	header := make([]byte, headerSize)
+	if _, err := s.dev.ReadAt(header, s.offset); err != nil {
+		return ninja.Trim{}, err
+	}
+	if string(header[:len(magic)]) != magic {
+		return ninja.Trim{}, ErrNotFound
+	}
+
+	length := int64(binary.LittleEndian.Uint16(header[6:]))
+	if s.offset+headerSize+length+checksumSize > s.dev.Size() {
+		return ninja.Trim{}, ErrChecksum
+	}
+	record := make([]byte, headerSize+length+checksumSize)
+	if _, err := s.dev.ReadAt(record, s.offset); err != nil {
+		return ninja.Trim{}, err
+	}
+	return decodeTrim(record)
+}
+
+// SaveTrim erases the flash memory blocks holding the trim and writes the new trim.
+// It returns ErrInvalidOffset if the offset is not aligned to the erase block size or
+// the erased blocks do not fit in the device.
+func (s *FlashStore) SaveTrim(trim ninja.Trim) error {
+	eraseSize := s.dev.EraseBlockSize()
+	writeSize := s.dev.WriteBlockSize()
+	if eraseSize <= 0 || writeSize <= 0 || s.offset < 0 || s.offset%eraseSize != 0 {
+		return ErrInvalidOffset
+	}
+
+	record := encodeTrim(trim)
+	// pad the record to the write block size with the erased value
+	for int64(len(record))%writeSize != 0 {
+		record = append(record, 0xff)
+	}
+
+	blocks := (int64(len(record)) + eraseSize - 1) / eraseSize
+	if s.offset+blocks*eraseSize > s.dev.Size() {
+		return ErrInvalidOffset
+	}
+	if err := s.dev.EraseBlocks(s.offset/eraseSize, blocks); err != nil {
+		return err
+	}
+	_, err := s.dev.WriteAt(record, s.offset)
+	return err
+}
@@ -0,0 +1,90 @@
+// Package settings provides persistent storage for the robot settings,
+// so a calibrated robot keeps its trim across reboots.
+package settings
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+// Store is an interface that abstracts persistent storage of the trim.
+type Store interface {
+	// LoadTrim loads the stored trim.
+	// It returns ErrNotFound if no trim was saved yet.
+	LoadTrim() (ninja.Trim, error)
+	// SaveTrim saves the trim, replacing the previously saved one.
+	SaveTrim(trim ninja.Trim) error
+}
+
+var (
+	ErrNotFound           = errors.New("settings: not found")
+	ErrChecksum           = errors.New("settings: checksum mismatch")
+	ErrUnsupportedVersion = errors.New("settings: unsupported version")
+	ErrInvalidOffset      = errors.New("settings: invalid offset")
+)
+
+// Record layout (little endian):
+//
+//	magic   [4]byte "GOTT"
+//	version uint16
+//	length  uint16 payload length
+//	payload [length]byte
+//	crc     uint32 CRC-32 (IEEE) of version, length and payload
+const (
+	magic           = "GOTT"
+	headerSize      = 8
+	checksumSize    = 4
+	trimVersion     = 1
+	trimPayloadSize = 36
+	trimRecordSize  = headerSize + trimPayloadSize + checksumSize
+)
+
+// encodeTrim encodes the trim into a record with a version and checksum.
+func encodeTrim(trim ninja.Trim) []byte {
+	b := make([]byte, 0, trimRecordSize)
+	b = append(b, magic...)
+	b = binary.LittleEndian.AppendUint16(b, trimVersion)
+	b = binary.LittleEndian.AppendUint16(b, trimPayloadSize)
+	b = binary.LittleEndian.AppendUint32(b, uint32(int32(trim.TiltAngle)))
+	b = binary.LittleEndian.AppendUint64(b, uint64(trim.LeftStepDuration))
+	b = binary.LittleEndian.AppendUint64(b, uint64(trim.RightStepDuration))
+	b = binary.LittleEndian.AppendUint32(b, uint32(int32(trim.LfSpeed)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(int32(trim.RfSpeed)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(int32(trim.LlAngle)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(int32(trim.RlAngle)))
+	return binary.LittleEndian.AppendUint32(b, crc32.ChecksumIEEE(b[len(magic):]))
+}
+
+// decodeTrim decodes a record created by encodeTrim, verifying its version and checksum.
+func decodeTrim(b []byte) (ninja.Trim, error) {
+	if len(b) < headerSize || string(b[:len(magic)]) != magic {
+		return ninja.Trim{}, ErrNotFound
+	}
+	version := binary.LittleEndian.Uint16(b[4:])
+	length := int(binary.LittleEndian.Uint16(b[6:]))
+	if len(b) < headerSize+length+checksumSize {
+		return ninja.Trim{}, ErrChecksum
+	}
+	checksum := binary.LittleEndian.Uint32(b[headerSize+length:])
+	if crc32.ChecksumIEEE(b[len(magic):headerSize+length]) != checksum {
+		return ninja.Trim{}, ErrChecksum
+	}
+	if version != trimVersion || length != trimPayloadSize {
+		return ninja.Trim{}, ErrUnsupportedVersion
+	}
+
+	p := b[headerSize : headerSize+length]
+	return ninja.Trim{
+		TiltAngle:         int(int32(binary.LittleEndian.Uint32(p[0:]))),
+		LeftStepDuration:  time.Duration(binary.LittleEndian.Uint64(p[4:])),
+		RightStepDuration: time.Duration(binary.LittleEndian.Uint64(p[12:])),
+		LfSpeed:           int(int32(binary.LittleEndian.Uint32(p[20:]))),
+		RfSpeed:           int(int32(binary.LittleEndian.Uint32(p[24:]))),
+		LlAngle:           int(int32(binary.LittleEndian.Uint32(p[28:]))),
+		RlAngle:           int(int32(binary.LittleEndian.Uint32(p[32:]))),
+	}, nil
+}
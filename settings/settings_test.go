@@ -0,0 +1,216 @@
+package settings
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+var testTrim = ninja.Trim{
+	TiltAngle:         -5,
+	LeftStepDuration:  120 * time.Millisecond,
+	RightStepDuration: -80 * time.Millisecond,
+	LfSpeed:           3,
+	RfSpeed:           -2,
+	LlAngle:           7,
+	RlAngle:           -4,
+}
+
+// resign recomputes the checksum of a record after it was modified.
+func resign(record []byte) {
+	end := len(record) - checksumSize
+	binary.LittleEndian.PutUint32(record[end:], crc32.ChecksumIEEE(record[len(magic):end]))
+}
+
+func TestEncodeDecodeTrim(t *testing.T) {
+	record := encodeTrim(testTrim)
+	if len(record) != trimRecordSize {
+		t.Fatalf("len(encodeTrim()) = %d, want %d", len(record), trimRecordSize)
+	}
+	got, err := decodeTrim(record)
+	if err != nil {
+		t.Fatalf("decodeTrim() error = %v", err)
+	}
+	if got != testTrim {
+		t.Errorf("decodeTrim() = %+v, want %+v", got, testTrim)
+	}
+}
+
+func TestDecodeTrimErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func([]byte) []byte
+		want   error
+	}{
+		{
+			name:   "erased",
+			modify: func(b []byte) []byte { return make([]byte, len(b)) },
+			want:   ErrNotFound,
+		},
+		{
+			name:   "too short for a header",
+			modify: func(b []byte) []byte { return b[:headerSize-1] },
+			want:   ErrNotFound,
+		},
+		{
+			name:   "truncated",
+			modify: func(b []byte) []byte { return b[:len(b)-1] },
+			want:   ErrChecksum,
+		},
+		{
+			name: "corrupt payload",
+			modify: func(b []byte) []byte {
+				b[headerSize] ^= 0x01
+				return b
+			},
+			want: ErrChecksum,
+		},
+		{
+			name: "corrupt checksum",
+			modify: func(b []byte) []byte {
+				b[len(b)-1] ^= 0x80
+				return b
+			},
+			want: ErrChecksum,
+		},
+		{
+			name: "wrong version",
+			modify: func(b []byte) []byte {
+				binary.LittleEndian.PutUint16(b[4:], trimVersion+1)
+				resign(b)
+				return b
+			},
+			want: ErrUnsupportedVersion,
+		},
+		{
+			name: "wrong payload length",
+			modify: func(b []byte) []byte {
+				binary.LittleEndian.PutUint16(b[6:], trimPayloadSize-4)
+				b = append(b[:headerSize+trimPayloadSize-4], make([]byte, checksumSize)...)
+				resign(b)
+				return b
+			},
+			want: ErrUnsupportedVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeTrim(tt.modify(encodeTrim(testTrim)))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("decodeTrim() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	s := NewFileStore(filepath.Join(t.TempDir(), "trim"))
+	if _, err := s.LoadTrim(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("LoadTrim() before save error = %v, want %v", err, ErrNotFound)
+	}
+	if err := s.SaveTrim(ninja.Trim{TiltAngle: 1}); err != nil {
+		t.Fatalf("SaveTrim() error = %v", err)
+	}
+	if err := s.SaveTrim(testTrim); err != nil {
+		t.Fatalf("SaveTrim() error = %v", err)
+	}
+	got, err := s.LoadTrim()
+	if err != nil {
+		t.Fatalf("LoadTrim() error = %v", err)
+	}
+	if got != testTrim {
+		t.Errorf("LoadTrim() = %+v, want %+v", got, testTrim)
+	}
+}
+
+// memoryFlash is a BlockDevice in memory that, like flash memory, can only clear bits when writing.
+type memoryFlash struct {
+	data                 []byte
+	writeSize, eraseSize int64
+}
+
+func newMemoryFlash(size int) *memoryFlash {
+	f := &memoryFlash{data: make([]byte, size), writeSize: 4, eraseSize: 64}
+	for i := range f.data {
+		f.data[i] = 0xff
+	}
+	return f
+}
+
+func (f *memoryFlash) ReadAt(p []byte, off int64) (int, error) {
+	return copy(p, f.data[off:]), nil
+}
+
+func (f *memoryFlash) WriteAt(p []byte, off int64) (int, error) {
+	for i, b := range p {
+		f.data[off+int64(i)] &= b
+	}
+	return len(p), nil
+}
+
+func (f *memoryFlash) Size() int64           { return int64(len(f.data)) }
+func (f *memoryFlash) WriteBlockSize() int64 { return f.writeSize }
+func (f *memoryFlash) EraseBlockSize() int64 { return f.eraseSize }
+
+func (f *memoryFlash) EraseBlocks(start, len int64) error {
+	for i := start * f.eraseSize; i < (start+len)*f.eraseSize; i++ {
+		f.data[i] = 0xff
+	}
+	return nil
+}
+
+func TestFlashStore(t *testing.T) {
+	dev := newMemoryFlash(256)
+	s := NewFlashStore(dev, 128)
+	if _, err := s.LoadTrim(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("LoadTrim() before save error = %v, want %v", err, ErrNotFound)
+	}
+	if err := s.SaveTrim(ninja.Trim{TiltAngle: 1}); err != nil {
+		t.Fatalf("SaveTrim() error = %v", err)
+	}
+	if err := s.SaveTrim(testTrim); err != nil {
+		t.Fatalf("SaveTrim() error = %v", err)
+	}
+	got, err := s.LoadTrim()
+	if err != nil {
+		t.Fatalf("LoadTrim() error = %v", err)
+	}
+	if got != testTrim {
+		t.Errorf("LoadTrim() = %+v, want %+v", got, testTrim)
+	}
+
+	for _, offset := range []int64{-64, 100, 256} {
+		if err := NewFlashStore(dev, offset).SaveTrim(testTrim); !errors.Is(err, ErrInvalidOffset) {
+			t.Errorf("SaveTrim() at offset %d error = %v, want %v", offset, err, ErrInvalidOffset)
+		}
+	}
+}
+
+func TestFlashStoreInvalidDevice(t *testing.T) {
+	tests := []struct {
+		name                 string
+		size                 int
+		writeSize, eraseSize int64
+		offset               int64
+	}{
+		{"zero erase block size", 256, 4, 0, 0},
+		{"zero write block size", 256, 0, 64, 0},
+		{"negative write block size", 256, -4, 64, 0},
+		{"padded record past the end", 256, 64, 16, 208},
+		{"erased blocks past the end", 250, 4, 64, 192},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dev := newMemoryFlash(tt.size)
+			dev.writeSize, dev.eraseSize = tt.writeSize, tt.eraseSize
+			if err := NewFlashStore(dev, tt.offset).SaveTrim(testTrim); !errors.Is(err, ErrInvalidOffset) {
+				t.Errorf("SaveTrim() error = %v, want %v", err, ErrInvalidOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Package sfx generates procedural sound effects, such as sweeps, chirps, sirens,
+// alarms and R2-D2 style babble, played directly on a buzzer PWM channel.
+// Unlike melodies made of buzzer notes, the frequency changes continuously.
+package sfx
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/clock"
+)
+
+// step is the interval at which the frequency is updated during a sweep.
+const step = 2 * time.Millisecond
+
+// Curve determines how the frequency changes from the start to the end of a sweep.
+type Curve int
+
+const (
+	// CurveLinear changes the frequency at a constant rate in Hz.
+	CurveLinear Curve = iota
+	// CurveExponential changes the pitch at a constant rate, which sounds even to the ear.
+	CurveExponential
+	// CurveEaseInOut changes the frequency slowly at the start and end, following a half cosine wave.
+	CurveEaseInOut
+)
+
+// Sweep represents a sound whose frequency changes continuously from From to To.
+// A sweep with equal frequencies is a plain tone and a sweep with zero frequencies is silence.
+type Sweep struct {
+	// From is the frequency at the start of the sweep in Hz.
+	From float64
+	// To is the frequency at the end of the sweep in Hz.
+	To float64
+	// Duration is the duration of the sweep.
+	Duration time.Duration
+	// Curve is the curve the frequency follows.
+	Curve Curve
+}
+
+// at returns the frequency at the given fraction of the sweep (0-1).
+func (s Sweep) at(f float64) float64 {
+	switch s.Curve {
+	case CurveExponential:
+		if s.From > 0 && s.To > 0 {
+			return s.From * math.Pow(s.To/s.From, f)
+		}
+	case CurveEaseInOut:
+		f = (1 - math.Cos(math.Pi*f)) / 2
+	}
+	return s.From + (s.To-s.From)*f
+}
+
+// Effect is a sound effect made of sweeps played one after another.
+// Effects can be concatenated with append.
+type Effect []Sweep
+
+// Duration returns the total duration of the effect.
+func (e Effect) Duration() time.Duration {
+	var d time.Duration
+	for _, s := range e {
+		d += s.Duration
+	}
+	return d
+}
+
+// Tone returns an effect playing a constant frequency, or silence if it is zero.
+func Tone(hz float64, d time.Duration) Effect {
+	return Effect{{From: hz, To: hz, Duration: d}}
+}
+
+// Chirp returns a single sweep from one frequency to another with an exponential curve.
+func Chirp(from, to float64, d time.Duration) Effect {
+	return Effect{{From: from, To: to, Duration: d, Curve: CurveExponential}}
+}
+
+// Siren returns an effect that rises from low to high and falls back, the given number of cycles.
+// A negative number of cycles is treated as zero.
+func Siren(low, high float64, cycle time.Duration, cycles int) Effect {
+	cycles = max(cycles, 0)
+	e := make(Effect, 0, 2*cycles)
+	for range cycles {
+		e = append(e,
+			Sweep{From: low, To: high, Duration: cycle / 2, Curve: CurveEaseInOut},
+			Sweep{From: high, To: low, Duration: cycle - cycle/2, Curve: CurveEaseInOut},
+		)
+	}
+	return e
+}
+
+// Alarm returns an effect alternating between two frequencies for the given number of beeps each.
+// If the second frequency is zero, the beeps are separated by silence.
+// A negative count is treated as zero.
+func Alarm(first, second float64, beep time.Duration, count int) Effect {
+	count = max(count, 0)
+	e := make(Effect, 0, 2*count)
+	for range count {
+		e = append(e, Tone(first, beep)...)
+		e = append(e, Tone(second, beep)...)
+	}
+	return e
+}
+
+// Babble returns a random R2-D2 style babble of the given number of syllables,
+// each a short tone, chirp or warble, sometimes followed by a short pause.
+// If r is nil, a source seeded from the global random source is used.
+func Babble(r *rand.Rand, syllables int) Effect {
+	if r == nil {
+		r = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	freq := func() float64 {
+		return 800 + r.Float64()*2700
+	}
+	duration := func() time.Duration {
+		return time.Duration(30+r.IntN(90)) * time.Millisecond
+	}
+
+	var e Effect
+	for range syllables {
+		switch r.IntN(4) {
+		case 0:
+			e = append(e, Tone(freq(), duration())...)
+		case 1, 2:
+			e = append(e, Chirp(freq(), freq(), duration())...)
+		default:
+			low, high, d := freq(), freq(), duration()/2
+			e = append(e, Chirp(low, high, d)...)
+			e = append(e, Chirp(high, low, d)...)
+		}
+		if r.IntN(3) == 0 {
+			e = append(e, Tone(0, time.Duration(10+r.IntN(40))*time.Millisecond)...)
+		}
+	}
+	return e
+}
+
+// Synth plays sound effects on a buzzer PWM channel.
+type Synth struct {
+	ch     buzzer.PwmChannel
+	clock  clock.Clock
+	mu     sync.Mutex
+	volume int
+}
+
+// NewSynth creates a new Synth playing on the given PWM channel, which must already be configured.
+// The clock is used for timing. If it is nil, the system clock is used.
+func NewSynth(pwm buzzer.PwmChannel, clk clock.Clock) *Synth {
+	if clk == nil {
+		clk = clock.System
+	}
+	return &Synth{
+		ch:     pwm,
+		clock:  clk,
+		volume: buzzer.MaxVolume,
+	}
+}
+
+// SetVolume sets the volume of the effects from 0 (silent) to buzzer.MaxVolume, which is the default.
+// Like buzzer.Buzzer.SetVolume, it scales the duty cycle of the PWM channel.
+func (s *Synth) SetVolume(volume int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.volume = min(max(volume, 0), buzzer.MaxVolume)
+}
+
+// Play plays the effect and returns when it is done.
+func (s *Synth) Play(e Effect) error {
+	return s.PlayContext(context.Background(), e)
+}
+
+// PlayContext plays the effect and returns when it is done.
+// If ctx is cancelled, the effect stops and the context error is returned.
+func (s *Synth) PlayContext(ctx context.Context, e Effect) error {
+	defer s.ch.SetDuty(0)
+	for _, sweep := range e {
+		if err := s.sweep(ctx, sweep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Synth) sweep(ctx context.Context, sweep Sweep) error {
+	s.mu.Lock()
+	duty := uint32(uint64(s.ch.Top()/2) * uint64(s.volume) / buzzer.MaxVolume)
+	s.mu.Unlock()
+
+	start := s.clock.Now()
+	var period uint64
+	for {
+		at := s.clock.Now().Sub(start)
+		if at >= sweep.Duration {
+			return nil
+		}
+
+		if hz := sweep.at(float64(at) / float64(sweep.Duration)); hz > 0 && duty > 0 {
+			if p := uint64(1e9 / hz); p != period {
+				if err := s.ch.SetPeriod(p); err != nil {
+					return err
+				}
+				period = p
+			}
+			s.ch.SetDuty(duty)
+		} else {
+			s.ch.SetDuty(0)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(min(step, sweep.Duration-at)):
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package sim
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/clock"
+)
+
+// DefaultSampleRate is the sample rate used by a Piezo if none is given, in samples per second.
+const DefaultSampleRate = 44100
+
+// piezoTop is the maximum duty cycle value of a simulated piezo PWM channel.
+const piezoTop = 0xffff
+
+// amplitude is the amplitude of the synthesized square wave, half of the 16-bit range.
+const amplitude = math.MaxInt16 / 2
+
+// Piezo is a simulated buzzer PWM channel that synthesizes the square wave a real
+// buzzer would produce. It implements buzzer.PwmChannel, so melodies and sound effects
+// can be rendered on the host and written as a WAV file with WriteWAV.
+// The wave is rendered according to the clock, so with a clock.Virtual a long melody
+// is rendered instantly.
+type Piezo struct {
+	mu         sync.Mutex
+	clock      clock.Clock
+	sampleRate int
+	start      time.Time
+	period     uint64  // in nanoseconds
+	duty       uint32  // zero is silence
+	phase      float64 // position within the period in nanoseconds
+	samples    []int16
+}
+
+// NewPiezo creates a new Piezo rendering at the given sample rate, or DefaultSampleRate if it is zero.
+// Rendering starts at the current time of the clock. If the clock is nil, the system clock is used.
+func NewPiezo(clk clock.Clock, sampleRate int) *Piezo {
+	if clk == nil {
+		clk = clock.System
+	}
+	if sampleRate <= 0 {
+		sampleRate = DefaultSampleRate
+	}
+	return &Piezo{
+		clock:      clk,
+		sampleRate: sampleRate,
+		start:      clk.Now(),
+	}
+}
+
+// Configure implements buzzer.PwmChannel. It does nothing.
+func (p *Piezo) Configure() error {
+	return nil
+}
+
+// Top implements buzzer.PwmChannel.
+func (p *Piezo) Top() uint32 {
+	return piezoTop
+}
+
+// SetPeriod implements buzzer.PwmChannel. The period is in nanoseconds.
+func (p *Piezo) SetPeriod(period uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render()
+	p.period = period
+	return nil
+}
+
+// SetDuty implements buzzer.PwmChannel.
+func (p *Piezo) SetDuty(value uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render()
+	p.duty = min(value, piezoTop)
+}
+
+// render renders the samples up to the current time with the current period and duty.
+func (p *Piezo) render() {
+	elapsed := p.clock.Now().Sub(p.start)
+	target := int(elapsed.Seconds() * float64(p.sampleRate))
+	step := 1e9 / float64(p.sampleRate)
+	for len(p.samples) < target {
+		var sample int16
+		if p.duty > 0 && p.period > 0 {
+			period := float64(p.period)
+			p.phase = math.Mod(p.phase+step, period)
+			sample = -amplitude
+			if p.phase < period*float64(p.duty)/piezoTop {
+				sample = amplitude
+			}
+		}
+		p.samples = append(p.samples, sample)
+	}
+}
+
+// Duration returns the duration of the sound rendered so far.
+func (p *Piezo) Duration() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render()
+	return time.Duration(len(p.samples)) * time.Second / time.Duration(p.sampleRate)
+}
+
+// Reset discards the sound rendered so far and silences the piezo.
+// Rendering starts again at the current time of the clock.
+func (p *Piezo) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.start = p.clock.Now()
+	p.duty = 0
+	p.phase = 0
+	p.samples = nil
+}
+
+// WriteWAV renders the sound up to the current time and writes it to w
+// as a mono 16-bit PCM WAV file.
+func (p *Piezo) WriteWAV(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render()
+
+	const bytesPerSample = 2
+	dataSize := uint32(len(p.samples) * bytesPerSample)
+	header := struct {
+		Riff          [4]byte
+		Size          uint32
+		Wave          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		Riff:          [4]byte{'R', 'I', 'F', 'F'},
+		Size:          36 + dataSize,
+		Wave:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		Format:        1, // PCM
+		Channels:      1,
+		SampleRate:    uint32(p.sampleRate),
+		ByteRate:      uint32(p.sampleRate * bytesPerSample),
+		BlockAlign:    bytesPerSample,
+		BitsPerSample: 8 * bytesPerSample,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      dataSize,
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, p.samples)
+}
@@ -0,0 +1,63 @@
+package sim
+
+import (
+	"github.com/HattoriHanzo031/gotto/buzzer"
+	"github.com/HattoriHanzo031/gotto/clock"
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+// Robot holds the simulated servos for all four joints of the Ninja robot.
+type Robot struct {
+	LeftLeg   *Servo180
+	RightLeg  *Servo180
+	LeftFoot  *Servo360
+	RightFoot *Servo360
+	// Clock is the clock used to timestamp servo commands and to time the robot's motions.
+	Clock clock.Clock
+}
+
+// NewRobot creates a new Robot with a fresh simulated servo for each joint.
+// If the clock is nil, the system clock is used. Use a clock.Virtual
+// to run the robot's motions in virtual time.
+func NewRobot(clk clock.Clock) *Robot {
+	if clk == nil {
+		clk = clock.System
+	}
+	return &Robot{
+		LeftLeg:   NewServo180("left leg", clk),
+		RightLeg:  NewServo180("right leg", clk),
+		LeftFoot:  NewServo360("left foot", clk),
+		RightFoot: NewServo360("right foot", clk),
+		Clock:     clk,
+	}
+}
+
+// Ninja creates a new ninja.Ninja driven by the robot's simulated servos and clock.
+// The buzzer can be nil if not used.
+// The Ninja uses ninja.DefaultConfig, use NinjaWithConfig for other builds.
+func (r *Robot) Ninja(bz *buzzer.Buzzer) *ninja.Ninja {
+	return r.NinjaWithConfig(bz, ninja.DefaultConfig)
+}
+
+// NinjaWithConfig is like Ninja but uses the given configuration instead of ninja.DefaultConfig.
+func (r *Robot) NinjaWithConfig(bz *buzzer.Buzzer, cfg ninja.Config) *ninja.Ninja {
+	return ninja.NewWithConfig(r.RightLeg, r.LeftLeg, r.RightFoot, r.LeftFoot, bz, r.Clock, cfg)
+}
+
+// Timelines returns the timelines of all joints keyed by joint name.
+func (r *Robot) Timelines() map[string]Timeline {
+	return map[string]Timeline{
+		r.LeftLeg.Name():   r.LeftLeg.Timeline(),
+		r.RightLeg.Name():  r.RightLeg.Timeline(),
+		r.LeftFoot.Name():  r.LeftFoot.Timeline(),
+		r.RightFoot.Name(): r.RightFoot.Timeline(),
+	}
+}
+
+// Reset clears the timelines of all joints.
+func (r *Robot) Reset() {
+	r.LeftLeg.Reset()
+	r.RightLeg.Reset()
+	r.LeftFoot.Reset()
+	r.RightFoot.Reset()
+}
@@ -0,0 +1,152 @@
+package sim
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/clock"
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+// Pose represents the position and heading of the robot on the floor.
+// The robot starts at the origin facing along the X axis, and the Y axis points to its left.
+type Pose struct {
+	X, Y float64 // in millimeters
+	// Heading is the direction the robot faces, in radians.
+	// Positive values are counterclockwise (to the left).
+	Heading float64
+}
+
+// Drive describes the geometry of the robot in roll mode, where the feet act as the wheels
+// of a differential drive. Non-positive fields are replaced with the DefaultDrive values.
+type Drive struct {
+	// WheelRadius is the radius of the feet in millimeters.
+	WheelRadius float64
+	// TrackWidth is the distance between the feet in millimeters.
+	TrackWidth float64
+	// MaxRPM is the rotation speed of a foot servo at 100% speed.
+	// Foot speed is assumed to be proportional to the speed percentage.
+	MaxRPM float64
+}
+
+// DefaultDrive approximates the Otto ninja robot with SG90 continuous rotation servos.
+var DefaultDrive = Drive{
+	WheelRadius: 22,
+	TrackWidth:  80,
+	MaxRPM:      100,
+}
+
+// withDefaults returns the drive with its non-positive fields replaced with the DefaultDrive values.
+func (d Drive) withDefaults() Drive {
+	if d.WheelRadius <= 0 {
+		d.WheelRadius = DefaultDrive.WheelRadius
+	}
+	if d.TrackWidth <= 0 {
+		d.TrackWidth = DefaultDrive.TrackWidth
+	}
+	if d.MaxRPM <= 0 {
+		d.MaxRPM = DefaultDrive.MaxRPM
+	}
+	return d
+}
+
+// wheelSpeed converts foot speed in percentage to linear speed in mm/s.
+func (d Drive) wheelSpeed(speed int) float64 {
+	return float64(speed) / 100 * d.MaxRPM / 60 * 2 * math.Pi * d.WheelRadius
+}
+
+// Integrate computes the pose of the robot after rolling from the from time to the to time,
+// starting at the origin. left and right are the timelines of the left and right foot servos.
+// The right foot servo is mounted mirrored, so negative right speeds move the robot forward,
+// the same as the speeds sent by ninja.Roll.
+// It also returns the distance travelled along the path in millimeters.
+func (d Drive) Integrate(left, right Timeline, from, to time.Time) (Pose, float64) {
+	d = d.withDefaults()
+	times := []time.Time{from, to}
+	for _, tl := range []Timeline{left, right} {
+		for _, e := range tl {
+			if e.Time.After(from) && e.Time.Before(to) {
+				times = append(times, e.Time)
+			}
+		}
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return a.Compare(b) })
+
+	var pose Pose
+	var distance float64
+	for i := 1; i < len(times); i++ {
+		dt := times[i].Sub(times[i-1]).Seconds()
+		l, _ := left.At(times[i-1])
+		r, _ := right.At(times[i-1])
+		vl := d.wheelSpeed(l)
+		vr := d.wheelSpeed(-r)
+
+		v := (vl + vr) / 2
+		w := (vr - vl) / d.TrackWidth
+		if math.Abs(w) < 1e-9 {
+			pose.X += v * dt * math.Cos(pose.Heading)
+			pose.Y += v * dt * math.Sin(pose.Heading)
+		} else {
+			heading := pose.Heading + w*dt
+			pose.X += v / w * (math.Sin(heading) - math.Sin(pose.Heading))
+			pose.Y -= v / w * (math.Cos(heading) - math.Cos(pose.Heading))
+			pose.Heading = heading
+		}
+		distance += math.Abs(v) * dt
+	}
+	return pose, distance
+}
+
+// RollReport summarizes the motion of the robot while rolling.
+type RollReport struct {
+	// Pose is the final pose of the robot.
+	Pose Pose
+	// Distance is the distance travelled along the path in millimeters.
+	Distance float64
+	// Drift is the sideways offset from the starting line in millimeters.
+	// Positive values are to the left.
+	Drift float64
+	// Curvature is the change of heading per travelled distance in radians per millimeter.
+	// Positive values curve to the left, zero means the robot rolls straight.
+	Curvature float64
+}
+
+// SimulateRoll simulates the robot with the given trim rolling with the given throttle
+// and turn for the given duration and reports how it moved.
+// The simulation runs in virtual time and returns immediately. The robot uses ninja.DefaultConfig,
+// use SimulateRollConfig for other builds.
+func (d Drive) SimulateRoll(trim ninja.Trim, throttle, turn int, duration time.Duration) (RollReport, error) {
+	return d.SimulateRollConfig(ninja.DefaultConfig, trim, throttle, turn, duration)
+}
+
+// SimulateRollConfig is like SimulateRoll but the robot uses the given configuration.
+func (d Drive) SimulateRollConfig(cfg ninja.Config, trim ninja.Trim, throttle, turn int, duration time.Duration) (RollReport, error) {
+	clk := clock.NewVirtual(time.Time{})
+	r := NewRobot(clk)
+	n := r.NinjaWithConfig(nil, cfg)
+	n.Trim(trim)
+	if err := n.Mode(ninja.ModeRoll); err != nil {
+		return RollReport{}, err
+	}
+
+	start := clk.Now()
+	if err := n.Roll(throttle, turn); err != nil {
+		return RollReport{}, err
+	}
+	clk.Sleep(duration)
+	if err := n.RollStop(); err != nil {
+		return RollReport{}, err
+	}
+
+	pose, distance := d.Integrate(r.LeftFoot.Timeline(), r.RightFoot.Timeline(), start, clk.Now())
+	report := RollReport{
+		Pose:     pose,
+		Distance: distance,
+		Drift:    pose.Y,
+	}
+	if distance > 0 {
+		report.Curvature = pose.Heading / distance
+	}
+	return report, nil
+}
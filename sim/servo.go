@@ -0,0 +1,133 @@
+// Package sim provides simulated Ninja hardware that runs on the host.
+// It can be used to drive a ninja.Ninja without a physical robot and inspect
+// exactly what each servo was told to do.
+package sim
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/clock"
+)
+
+var (
+	ErrInvalidAngle = errors.New("sim: invalid angle")
+	ErrInvalidSpeed = errors.New("sim: invalid speed")
+)
+
+// Event represents a single command received by a simulated servo.
+type Event struct {
+	// Time is the time at which the command was received.
+	Time time.Time
+	// Value is the angle (for Servo180) or the speed (for Servo360) that was set.
+	Value int
+}
+
+// Timeline is a time ordered list of commands received by a simulated servo.
+type Timeline []Event
+
+// At returns the value in effect at the given time.
+// The second return value is false if no command was received before or at that time.
+func (tl Timeline) At(t time.Time) (int, bool) {
+	value, ok := 0, false
+	for _, e := range tl {
+		if e.Time.After(t) {
+			break
+		}
+		value, ok = e.Value, true
+	}
+	return value, ok
+}
+
+// Last returns the last command in the timeline.
+// The second return value is false if the timeline is empty.
+func (tl Timeline) Last() (Event, bool) {
+	if len(tl) == 0 {
+		return Event{}, false
+	}
+	return tl[len(tl)-1], true
+}
+
+// joint records the commands received by a simulated servo.
+type joint struct {
+	mu       sync.Mutex
+	name     string
+	clock    clock.Clock
+	timeline Timeline
+}
+
+func newJoint(name string, clk clock.Clock) joint {
+	if clk == nil {
+		clk = clock.System
+	}
+	return joint{name: name, clock: clk}
+}
+
+func (j *joint) record(value int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.timeline = append(j.timeline, Event{Time: j.clock.Now(), Value: value})
+}
+
+// Name returns the name of the joint the servo drives.
+func (j *joint) Name() string {
+	return j.name
+}
+
+// Timeline returns a copy of all commands received by the servo so far.
+func (j *joint) Timeline() Timeline {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append(Timeline(nil), j.timeline...)
+}
+
+// Reset clears the recorded timeline.
+func (j *joint) Reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.timeline = nil
+}
+
+// Servo180 is a simulated servo with 180 degrees of rotation.
+// It implements the servo.Servo180 interface.
+type Servo180 struct {
+	joint
+}
+
+// NewServo180 creates a new simulated 180-degree servo for the named joint.
+// Commands are timestamped using the given clock. If it is nil, the system clock is used.
+func NewServo180(name string, clk clock.Clock) *Servo180 {
+	return &Servo180{joint: newJoint(name, clk)}
+}
+
+// SetAngle records the angle of the servo in degrees (0-180).
+// Like the TinyGo servo driver, it returns an error for angles out of range.
+func (s *Servo180) SetAngle(angle int) error {
+	if angle < 0 || angle > 180 {
+		return ErrInvalidAngle
+	}
+	s.record(angle)
+	return nil
+}
+
+// Servo360 is a simulated continuous rotation servo.
+// It implements the servo.Servo360 interface.
+type Servo360 struct {
+	joint
+}
+
+// NewServo360 creates a new simulated continuous rotation servo for the named joint.
+// Commands are timestamped using the given clock. If it is nil, the system clock is used.
+func NewServo360(name string, clk clock.Clock) *Servo360 {
+	return &Servo360{joint: newJoint(name, clk)}
+}
+
+// SetSpeed records the speed of the servo in percentage (-100 to 100).
+func (s *Servo360) SetSpeed(speed int) error {
+	if speed < -100 || speed > 100 {
+		return ErrInvalidSpeed
+	}
+	s.record(speed)
+	return nil
+}
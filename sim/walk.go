@@ -0,0 +1,256 @@
+package sim
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/HattoriHanzo031/gotto/clock"
+	"github.com/HattoriHanzo031/gotto/ninja"
+)
+
+// Foot identifies one of the robot's feet.
+type Foot int
+
+const (
+	FootLeft Foot = iota
+	FootRight
+)
+
+// Gait describes how the robot moves in walk mode.
+// When the robot is tilted onto one foot, spinning that foot rotates the whole robot
+// around it, swinging the other foot. Alternating these pivots moves the robot.
+type Gait struct {
+	// FootSpacing is the distance between the centers of the feet in millimeters.
+	FootSpacing float64
+	// MaxRPM is the rotation speed of a foot servo at 100% speed.
+	MaxRPM float64
+	// Grip is the fraction of the foot rotation that turns the robot, the rest is lost to slipping.
+	Grip float64
+	// MinTilt is the tilt angle in degrees needed to start lifting the swinging foot.
+	// Below it the swinging foot drags and the robot does not move.
+	MinTilt float64
+	// FullTilt is the tilt angle in degrees at which the swinging foot is fully lifted.
+	// Between MinTilt and FullTilt the pivot is proportionally less effective.
+	FullTilt float64
+}
+
+// DefaultGait approximates the Otto ninja robot with SG90 servos.
+var DefaultGait = Gait{
+	FootSpacing: 80,
+	MaxRPM:      100,
+	Grip:        0.3,
+	MinTilt:     20,
+	FullTilt:    45,
+}
+
+// Step represents a single pivot of the robot around one foot.
+type Step struct {
+	// Stance is the foot the robot stood and pivoted on.
+	Stance Foot
+	// Start is the time at which the pivot started.
+	Start time.Time
+	// Duration is the time the stance foot was spinning.
+	Duration time.Duration
+	// Rotation is the rotation of the robot around the stance foot in radians.
+	// Positive values are counterclockwise.
+	Rotation float64
+	// Pose is the pose of the robot after the step.
+	Pose Pose
+}
+
+// Stride represents the displacement of the robot over a pair of steps, one on each foot,
+// such as the ones performed by ninja.Walk for each step.
+type Stride struct {
+	// Distance is the distance between the poses before and after the stride in millimeters.
+	Distance float64
+	// Direction is the direction of the displacement in radians, relative to the heading
+	// before the stride. Zero is straight ahead, positive values are to the left.
+	Direction float64
+	// Turn is the change of heading over the stride in radians.
+	// Positive values turn to the left.
+	Turn float64
+}
+
+// WalkReport summarizes the motion of the robot while walking.
+type WalkReport struct {
+	// Steps are all the pivots the robot performed.
+	Steps []Step
+	// Strides are the pairs of consecutive steps.
+	Strides []Stride
+	// Pose is the final pose of the robot.
+	Pose Pose
+}
+
+type point struct {
+	x, y float64
+}
+
+// rotate rotates p around the center c by the given angle.
+func (p point) rotate(c point, angle float64) point {
+	sin, cos := math.Sincos(angle)
+	dx, dy := p.x-c.x, p.y-c.y
+	return point{
+		x: c.x + dx*cos - dy*sin,
+		y: c.y + dx*sin + dy*cos,
+	}
+}
+
+// pose returns the pose of the robot standing on the given feet.
+// The robot is between the feet and faces perpendicular to the line connecting them.
+func pose(left, right point) Pose {
+	return Pose{
+		X:       (left.x + right.x) / 2,
+		Y:       (left.y + right.y) / 2,
+		Heading: math.Atan2(-(left.x - right.x), left.y-right.y),
+	}
+}
+
+// efficiency returns the fraction of the pivot that is effective at the given tilt angle.
+func (g Gait) efficiency(tilt float64) float64 {
+	if g.FullTilt <= g.MinTilt {
+		if tilt >= g.MinTilt {
+			return 1
+		}
+		return 0
+	}
+	return min(max((tilt-g.MinTilt)/(g.FullTilt-g.MinTilt), 0), 1)
+}
+
+// yawRate returns the rotation speed of the robot in rad/s when pivoting on a foot spinning at the given speed.
+func (g Gait) yawRate(speed int) float64 {
+	return float64(speed) / 100 * g.MaxRPM / 60 * 2 * math.Pi * g.Grip
+}
+
+// Replay computes the steps of the robot between the from and to times from the timelines
+// of its servos, starting at the origin. The trim must be the one used by the ninja.Ninja
+// that drove the robot, since the tilt is measured from the trimmed home leg angles.
+func (g Gait) Replay(r *Robot, trim ninja.Trim, from, to time.Time) []Step {
+	ll, rl := r.LeftLeg.Timeline(), r.RightLeg.Timeline()
+	lf, rf := r.LeftFoot.Timeline(), r.RightFoot.Timeline()
+
+	times := []time.Time{from, to}
+	for _, tl := range []Timeline{ll, rl, lf, rf} {
+		for _, e := range tl {
+			if e.Time.After(from) && e.Time.Before(to) {
+				times = append(times, e.Time)
+			}
+		}
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return a.Compare(b) })
+	times = slices.CompactFunc(times, time.Time.Equal)
+
+	// home angles of the leg servos, see ninja lLegAngle and rLegAngle
+	lHome := float64(90 - trim.LlAngle)
+	rHome := float64(90 + trim.RlAngle)
+
+	left := point{0, g.FootSpacing / 2}
+	right := point{0, -g.FootSpacing / 2}
+	var steps []Step
+	for i := 1; i < len(times); i++ {
+		t, dt := times[i-1], times[i].Sub(times[i-1])
+		l, _ := ll.At(t)
+		r, _ := rl.At(t)
+		// both leg servo angles increase when tilting left and decrease when tilting right
+		tilt := ((float64(l) - lHome) + (float64(r) - rHome)) / 2
+
+		var stance Foot
+		var speed int
+		switch {
+		case tilt > 0:
+			stance = FootLeft
+			speed, _ = lf.At(t)
+		case tilt < 0:
+			stance = FootRight
+			speed, _ = rf.At(t)
+		}
+		rotation := g.yawRate(speed) * g.efficiency(math.Abs(tilt)) * dt.Seconds()
+		if rotation == 0 {
+			continue
+		}
+
+		if stance == FootLeft {
+			right = right.rotate(left, rotation)
+		} else {
+			left = left.rotate(right, rotation)
+		}
+
+		if n := len(steps); n > 0 && steps[n-1].Stance == stance && steps[n-1].Start.Add(steps[n-1].Duration).Equal(t) {
+			steps[n-1].Duration += dt
+			steps[n-1].Rotation += rotation
+			steps[n-1].Pose = pose(left, right)
+			continue
+		}
+		steps = append(steps, Step{
+			Stance:   stance,
+			Start:    t,
+			Duration: dt,
+			Rotation: rotation,
+			Pose:     pose(left, right),
+		})
+	}
+	return steps
+}
+
+// Strides groups the steps into pairs and computes the displacement over each pair.
+// A trailing unpaired step is ignored.
+func Strides(steps []Step) []Stride {
+	var strides []Stride
+	before := Pose{}
+	for i := 0; i+1 < len(steps); i += 2 {
+		after := steps[i+1].Pose
+		dx, dy := after.X-before.X, after.Y-before.Y
+		strides = append(strides, Stride{
+			Distance:  math.Hypot(dx, dy),
+			Direction: wrapAngle(math.Atan2(dy, dx) - before.Heading),
+			Turn:      wrapAngle(after.Heading - before.Heading),
+		})
+		before = after
+	}
+	return strides
+}
+
+// wrapAngle returns the given angle in radians normalized to (-π, π].
+func wrapAngle(a float64) float64 {
+	a = math.Mod(a, 2*math.Pi)
+	switch {
+	case a > math.Pi:
+		a -= 2 * math.Pi
+	case a <= -math.Pi:
+		a += 2 * math.Pi
+	}
+	return a
+}
+
+// SimulateWalk simulates the robot with the given trim walking the given number of steps
+// with ninja.Walk and reports how it moved.
+// The simulation runs in virtual time and returns immediately. The robot uses ninja.DefaultConfig,
+// use SimulateWalkConfig for other builds.
+func (g Gait) SimulateWalk(trim ninja.Trim, steps int) (WalkReport, error) {
+	return g.SimulateWalkConfig(ninja.DefaultConfig, trim, steps)
+}
+
+// SimulateWalkConfig is like SimulateWalk but the robot uses the given configuration.
+func (g Gait) SimulateWalkConfig(cfg ninja.Config, trim ninja.Trim, steps int) (WalkReport, error) {
+	clk := clock.NewVirtual(time.Time{})
+	r := NewRobot(clk)
+	n := r.NinjaWithConfig(nil, cfg)
+	n.Trim(trim)
+	if err := n.Home(); err != nil {
+		return WalkReport{}, err
+	}
+
+	start := clk.Now()
+	if err := n.Walk(steps); err != nil {
+		return WalkReport{}, err
+	}
+
+	report := WalkReport{
+		Steps: g.Replay(r, trim, start, clk.Now()),
+	}
+	report.Strides = Strides(report.Steps)
+	if len(report.Steps) > 0 {
+		report.Pose = report.Steps[len(report.Steps)-1].Pose
+	}
+	return report, nil
+}